@@ -0,0 +1,33 @@
+"""
+PrettyRandom: aesthetic and user-friendly random strings, e.g. for user IDs, ticket or order numbers.
+
+The generator is configured once and then called with a blocksize and a length:
+
+    from prettyrandom import PrettyRandom
+    generator = PrettyRandom(use_lowercase=True)
+    code = generator(blocksize=4, length=16)
+
+Public API:
+    PrettyRandom: The generator. See PrettyRandom.__init__() for all options.
+    Rule: The type of custom pattern rules, which receive the random source; see PrettyRandom.register_rule().
+    BloomFilter: A Bloom filter of issued codes, see the bloom_expected option.
+    SecureRandom: The random source of secure generators, see the secure option.
+    HOMOGLYPHS, CHARSETS, AMBIGUOUS_CHARACTERS, FONT_PROFILES: The character tables used by the corresponding options.
+    PROFILES: The options of common code formats, see PrettyRandom.from_profile().
+    Result, Block: The result of PrettyRandom.generate_detailed().
+    Reader: The endless stream returned by PrettyRandom.reader().
+    PrettyRandomError: The base class of all errors; see prettyrandom.errors for the specific ones.
+
+Run `python3 -m prettyrandom --help` for the command line interface.
+"""
+
+from .errors import PrettyRandomError, ConfigurationError, EmptyCharacterSetError, InvalidLengthError, GenerationError
+from .result import Result, Block
+from .stream import Reader
+from .generator import PrettyRandom, Rule, BloomFilter, SecureRandom, HOMOGLYPHS, CHARSETS, AMBIGUOUS_CHARACTERS, FONT_PROFILES, PROFILES
+
+
+__all__ = [
+    'PrettyRandom', 'Rule', 'BloomFilter', 'SecureRandom', 'HOMOGLYPHS', 'CHARSETS', 'AMBIGUOUS_CHARACTERS', 'FONT_PROFILES', 'PROFILES',
+    'Result', 'Block', 'Reader', 'PrettyRandomError', 'ConfigurationError', 'EmptyCharacterSetError', 'InvalidLengthError', 'GenerationError'
+]
@@ -0,0 +1,60 @@
+import argparse
+import sys
+from typing import Dict, List, Optional
+
+from .errors import PrettyRandomError
+from .generator import CHARSETS, PROFILES, PrettyRandom
+
+
+def main(argv: Optional[List[str]] = None) -> int:
+    """
+    Prints pretty random strings, one per line, to stdout or a file.
+
+    Args:
+        argv: The command line arguments; defaults to sys.argv.
+
+    Returns:
+        The exit code: 0 on success, 1 if the strings could not be generated or written, 2 for invalid arguments.
+    """
+    parser = argparse.ArgumentParser(prog="prettyrandom", description="Generate aesthetic and user-friendly random strings.")
+    parser.add_argument("--profile", choices=sorted(PROFILES), help="a common code format; further flags override its settings")
+    parser.add_argument("--blocksize", type=int, help="the size of each block (default: 4)")
+    parser.add_argument("--length", type=int, help="the number of characters without separators (default: 16)")
+    parser.add_argument("--count", type=int, default=1, help="the number of strings (default: 1)")
+    parser.add_argument("--charset", choices=sorted(CHARSETS), help="a predefined alphabet instead of numbers and uppercase letters")
+    parser.add_argument("--no-ambiguous", action="store_true", help="exclude easily confused characters such as 0/O and 1/I")
+    parser.add_argument("--separator", help="the string between blocks (default: a space)")
+    parser.add_argument("--seed", type=int, help="a seed making the output reproducible")
+    parser.add_argument("--out", help="a file to write to instead of stdout")
+    args = parser.parse_args(argv)
+
+    if args.count < 0:
+        parser.error("--count must not be negative")
+    if args.blocksize is not None and args.blocksize <= 0:
+        parser.error("--blocksize must be larger than zero")
+    if args.length is not None and args.length <= 0:
+        parser.error("--length must be larger than zero")
+
+    try:
+        # Only flags given on the command line override the profile
+        options: Dict = {key: value for key, value in vars(args).items() if key in ('blocksize', 'length', 'charset', 'separator', 'seed') and value is not None}
+        if args.no_ambiguous:
+            options['exclude_ambiguous'] = True
+        if args.profile is None:
+            generator = PrettyRandom(**{'blocksize': 4, 'length': 16, **options})
+        else:
+            generator = PrettyRandom.from_profile(args.profile, **options)
+        codes: List[str] = [generator() for _ in range(args.count)]
+        if args.out is None:
+            sys.stdout.write("".join(code + "\n" for code in codes))
+        else:
+            with open(args.out, "w", encoding="utf-8") as file:
+                file.write("".join(code + "\n" for code in codes))
+    except (PrettyRandomError, OSError) as error:
+        print(f"{parser.prog}: error: {error}", file=sys.stderr)
+        return 1
+    return 0
+
+
+if __name__ == "__main__":
+    sys.exit(main())
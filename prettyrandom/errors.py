@@ -0,0 +1,29 @@
+class PrettyRandomError(Exception):
+    """
+    Base class of all errors raised by PrettyRandom, so callers can handle them without matching error messages.
+    """
+
+
+class ConfigurationError(PrettyRandomError, ValueError):
+    """
+    Raised when the options of a PrettyRandom instance are unknown, invalid or conflicting.
+    """
+
+
+class EmptyCharacterSetError(ConfigurationError):
+    """
+    Raised when the options leave no characters in the character set.
+    """
+
+
+class InvalidLengthError(PrettyRandomError, ValueError):
+    """
+    Raised when a blocksize or length cannot be generated with the configuration.
+    """
+
+
+class GenerationError(PrettyRandomError, RuntimeError):
+    """
+    Raised when no string satisfying the constraints could be generated, e.g. after too many attempts
+    or because a pool of codes is exhausted.
+    """
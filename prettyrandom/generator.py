@@ -0,0 +1,2921 @@
+from collections import Counter
+from concurrent.futures import ProcessPoolExecutor
+from contextlib import contextmanager
+from itertools import repeat
+from typing import List, Callable, Dict, Iterator, Optional, TextIO, Tuple, Union
+import base64
+import copy
+import datetime
+import hashlib
+import hmac
+import json
+import functools
+import math
+import pickle
+import random
+import threading
+import time
+import unicodedata
+import uuid
+
+from .errors import ConfigurationError, EmptyCharacterSetError, GenerationError, InvalidLengthError
+from .result import Block, Result
+from .stream import Reader
+
+
+__all__ = ['PrettyRandom', 'Rule', 'BloomFilter', 'SecureRandom', 'HOMOGLYPHS', 'CHARSETS', 'AMBIGUOUS_CHARACTERS', 'FONT_PROFILES', 'PROFILES']
+
+
+# A custom rule renders a block from the generator's random source, two characters and the blocksize,
+# e.g. (rng, "A", "B", 4) -> "ABAB". It has to return exactly blocksize characters and should draw any
+# randomness from the given source, so that seeded(), clones, recipes and secure mode apply to it.
+Rule = Callable[[random.Random, str, str, int], str]
+
+
+# A rule bound to an instance, which draws from the instance's random source itself, e.g. a built-in rule method.
+BoundRule = Callable[[str, str, int], str]
+
+
+# Characters from other scripts that are visually identical to a Latin character.
+# Maps each lookalike to the Latin character it is confused with.
+HOMOGLYPHS: Dict[str, str] = {
+    # Cyrillic
+    '\u0410': 'A', '\u0412': 'B', '\u0415': 'E', '\u041a': 'K', '\u041c': 'M',
+    '\u041d': 'H', '\u041e': 'O', '\u0420': 'P', '\u0421': 'C', '\u0422': 'T',
+    '\u0425': 'X', '\u0406': 'I', '\u0408': 'J', '\u0405': 'S', '\u04ae': 'Y',
+    '\u0430': 'a', '\u0435': 'e', '\u043e': 'o', '\u0440': 'p', '\u0441': 'c',
+    '\u0443': 'y', '\u0445': 'x', '\u0456': 'i', '\u0458': 'j', '\u0455': 's',
+    # Greek
+    '\u0391': 'A', '\u0392': 'B', '\u0395': 'E', '\u0396': 'Z', '\u0397': 'H',
+    '\u0399': 'I', '\u039a': 'K', '\u039c': 'M', '\u039d': 'N', '\u039f': 'O',
+    '\u03a1': 'P', '\u03a4': 'T', '\u03a5': 'Y', '\u03a7': 'X', '\u03bf': 'o',
+    '\u03bd': 'v'
+}
+
+
+# Named alphabets for the charset option.
+CHARSETS: Dict[str, str] = {
+    'hex': "0123456789ABCDEF",
+    # RFC 4648
+    'base32': "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567",
+    # Douglas Crockford's base32, without I, L, O and U
+    'crockford': "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+}
+
+
+# Characters that are easily confused with each other when read aloud or typed: 0/O, 1/l/I, 5/S and 8/B.
+AMBIGUOUS_CHARACTERS: set[str] = set("0O1lI5S8B")
+
+
+# Characters that render legibly on constrained displays, by profile name.
+FONT_PROFILES: Dict[str, set[str]] = {
+    # Seven-segment displays can show all digits but only some letters, several of them only in one case
+    'seven_segment': set("0123456789AbCcdEFHhJLnoPrtUuy")
+}
+
+
+# Options for common code formats, by profile name. See PrettyRandom.from_profile().
+# Secrets such as PINs and tokens use uniform blocks only, since the pattern rules reduce the entropy.
+PROFILES: Dict[str, Dict] = {
+    # A1B2C-3D4E5-F6G7H-8J9K0-LMNPQ-R2D2X, the last group holds a Luhn mod 36 check character of each group
+    'license_key': {'use_numbers': True, 'use_uppercase': True, 'separator': "-", 'checksum_group': True, 'blocksize': 5, 'length': 25},
+    # 1234 5678 9012 3456
+    'gift_card': {'use_numbers': True, 'use_uppercase': False, 'blocksize': 4, 'length': 16},
+    # 4821
+    'pin': {'use_numbers': True, 'use_uppercase': False, 'rule_selection': 'base64ish', 'blocksize': 4, 'length': 4},
+    # 9F3A61C0 7B2ED845 ...
+    'hex_token': {'charset': 'hex', 'rule_selection': 'base64ish', 'blocksize': 8, 'length': 32}
+}
+
+
+class SecureRandom(random.SystemRandom):
+    """
+    A random source drawing from the operating system's cryptographically secure entropy source.
+    It has no state, so copies and pickles of it are new instances drawing from the same source.
+    If the entropy source fails, every draw raises a GenerationError instead of an OSError.
+    """
+
+    def __reduce__(self) -> Tuple[type, Tuple]:
+        return (SecureRandom, ())
+
+
+    def random(self) -> float:
+        try:
+            return super().random()
+        except (OSError, NotImplementedError) as error:
+            raise GenerationError("The entropy source failed.") from error
+
+
+    def getrandbits(self, k: int) -> int:
+        try:
+            return super().getrandbits(k)
+        except (OSError, NotImplementedError) as error:
+            raise GenerationError("The entropy source failed.") from error
+
+
+    def randbytes(self, n: int) -> bytes:
+        try:
+            return super().randbytes(n)
+        except (OSError, NotImplementedError) as error:
+            raise GenerationError("The entropy source failed.") from error
+
+
+class BloomFilter():
+    """
+    A Bloom filter of strings: a fixed-size bit array that answers membership queries without storing the strings.
+    Added strings are always found; other strings are falsely reported as contained with a small probability.
+    """
+
+    def __init__(self, expected: int, false_positive_rate: float) -> None:
+        """
+        Initializes an empty filter sized for the expected number of strings.
+
+        Args:
+            expected: The number of strings the filter is sized for.
+            false_positive_rate: The false positive rate after adding the expected number of strings, in (0, 1).
+
+        Raises:
+            ValueError: If expected is not positive or the false positive rate is not in (0, 1).
+        """
+        if expected <= 0:
+            raise ConfigurationError("The expected number of strings must be positive.")
+        if not 0 < false_positive_rate < 1:
+            raise ConfigurationError("The false positive rate must be in (0, 1).")
+
+        # Optimal number of bits and hash functions
+        self.size: int = max(8, math.ceil(-expected * math.log(false_positive_rate) / math.log(2) ** 2))
+        self.hashes: int = max(1, round(self.size / expected * math.log(2)))
+        self.bits: bytearray = bytearray((self.size + 7) // 8)
+
+
+    def positions(self, item: str) -> Iterator[int]:
+        """
+        Yields the bit positions of a string, derived from SHA-256 by double hashing.
+        """
+        digest: bytes = hashlib.sha256(item.encode("utf-8")).digest()
+        h1, h2 = int.from_bytes(digest[:8], "big"), int.from_bytes(digest[8:16], "big") | 1
+        for i in range(self.hashes):
+            yield (h1 + i * h2) % self.size
+
+
+    def add(self, item: str) -> None:
+        """
+        Adds a string to the filter.
+        """
+        for position in self.positions(item):
+            self.bits[position // 8] |= 1 << (position % 8)
+
+
+    def __contains__(self, item: str) -> bool:
+        """
+        Returns True if the string was probably added, False if it certainly was not.
+        """
+        return all(self.bits[position // 8] & (1 << (position % 8)) for position in self.positions(item))
+
+
+    def to_bytes(self) -> bytes:
+        """
+        Serializes the filter, e.g. to persist it between runs. See from_bytes().
+        """
+        return self.size.to_bytes(8, "big") + self.hashes.to_bytes(8, "big") + bytes(self.bits)
+
+
+    @classmethod
+    def from_bytes(cls, data: bytes) -> 'BloomFilter':
+        """
+        Restores a filter serialized by to_bytes().
+
+        Raises:
+            ValueError: If the data is not a serialized filter.
+        """
+        size, hashes = int.from_bytes(data[:8], "big"), int.from_bytes(data[8:16], "big")
+        if size <= 0 or hashes <= 0 or len(data) != 16 + (size + 7) // 8:
+            raise ValueError("The data is not a serialized Bloom filter.")
+        bloom: BloomFilter = cls.__new__(cls)
+        bloom.size, bloom.hashes, bloom.bits = size, hashes, bytearray(data[16:])
+        return bloom
+
+
+class ThreadLocal():
+    """
+    An instance attribute of PrettyRandom that can be overridden for the current thread only, see PrettyRandom.overridden().
+    Reads return the override of the current thread if there is one, otherwise the shared value; assignments set the shared value.
+    """
+
+    def __set_name__(self, owner: type, name: str) -> None:
+        self.name: str = name
+
+
+    def __get__(self, instance: Optional['PrettyRandom'], owner: Optional[type] = None) -> object:
+        if instance is None: return self
+        return getattr(instance.overrides, self.name, instance.__dict__[self.name])
+
+
+    def __set__(self, instance: 'PrettyRandom', value: object) -> None:
+        instance.__dict__[self.name] = value
+
+
+class PrettyRandom():
+    """
+    Generates aesthetic and user-friendly random strings.
+
+    Instances are thread-safe: state that changes during generation, such as the random source or the Bloom filter,
+    is guarded by a reentrant lock, so concurrent calls are serialized. Contexts such as seeded() and restricted()
+    only affect the current thread, so other threads keep generating and reading with the regular configuration.
+    For parallel generation without contention, give every thread its own clone().
+    """
+
+    # Maximum number of candidates generated before giving up on the configured constraints
+    max_attempts: int = 1000
+
+    # Attributes that seeded(), restricted() and recipes override for the current thread
+    rng = ThreadLocal()
+    character_set = ThreadLocal()
+    replaying = ThreadLocal()
+    trace = ThreadLocal()
+
+    def __init__(self, **kwargs) -> None:
+        """
+        Initializes an instance of the PrettyRandom class and
+        sets up the available rules and character sets.
+        By default, the character set includes numbers and uppercase letters.
+        
+        Args:
+            use_numbers: A boolean indicating whether to include numbers in the character set.
+            use_lowercase: A boolean indicating whether to include lowercase letters in the character set.
+            use_uppercase: A boolean indicating whether to include uppercase letters in the character set.
+            use_symbols: A boolean indicating whether to include the symbols !@#$%^&* in the character set.
+            exclude_ambiguous: A boolean indicating whether easily confused characters (0, O, 1, l, I, 5, S, 8, B) are
+                removed from the character set, whichever classes or alphabet it is built from.
+            exclude: Optional characters that are removed from the character set, e.g. "XYZ".
+            characters: An optional custom alphabet. If provided, it replaces the numbers, lowercase, uppercase and symbol sets.
+                Any Unicode characters are supported, e.g. "αβγδεζηθ".
+            charset: An optional name of a predefined alphabet used like characters: 'hex', 'base32' (RFC 4648)
+                or 'crockford' (Crockford's base32).
+            reject_homoglyphs: A boolean indicating whether visually identical characters from different scripts
+                raise an error instead of being dropped from the character set.
+            max_char_frequency: The maximum fraction of a generated string that any single character may occupy.
+                Strings exceeding it are regenerated, which effectively limits the repeat rule.
+            chained_checksums: A boolean indicating whether the last character of every block is a check character
+                over all preceding characters, see chain_checksums() and verify_chain().
+            allowlist: An optional pool of codes that generate_from_allowlist() draws from without repetition.
+            normalize_case: A boolean indicating whether normalize() uppercases codes.
+            normalize_separators: A boolean indicating whether normalize() strips separators (whitespace, '-', '_', '.').
+            separator: The string placed between blocks. An empty string joins the blocks without separator.
+            grouping: An optional number of characters per visual group, e.g. 4 for "A4A4-BBBB-1212". If provided, the
+                output is regrouped independently of the blocksize the rules operate on. See group().
+            padding_character: An optional character (e.g. '=') that replaces up to two trailing characters
+                of the last block, like base64 padding.
+            swap_probability: The probability in [0, 1] that a rule's two characters are swapped before it is applied.
+            seed: An optional seed making the output reproducible.
+            rng: An optional random.Random instance used as the source of all randomness, e.g. one seeded for
+                test fixtures. Defaults to a new instance seeded with seed, or from the system if no seed is given.
+            exact_composition: An optional mapping of character class ('numbers', 'lowercase', 'uppercase', 'symbols') to the exact
+                number of characters of that class, e.g. {'numbers': 4, 'uppercase': 4}. The characters are shuffled
+                and grouped into blocks instead of being generated by the rules.
+            shard_id: An optional node/shard identifier encoded into the leading characters, so that codes
+                of different shards never collide. Decode it with shard_of().
+            shard_bits: The number of bits reserved for the shard identifier.
+            block_initials: An optional acronym (e.g. "ACME") whose i-th letter becomes the first character of block i.
+            interleave: An optional pair of PrettyRandom instances. Even positions are taken from the first,
+                odd positions from the second, and the character set is the union of both.
+            banned_blocks: An optional collection of blocks (e.g. "0000") that are re-rolled whenever they are rendered.
+            error_correction: The number of trailing characters replaced by error-correcting check characters:
+                0 disables them, 1 detects any single-character error, 2 additionally corrects it, see correct().
+                Level 2 requires a character set of prime size larger than the length of the code.
+            font_profile: An optional name of a font profile (see FONT_PROFILES, e.g. 'seven_segment') restricting
+                the character set to characters that render legibly on that display.
+            time_bucket: An optional datetime.timedelta (e.g. 30 seconds). If set, the characters after the shard identifier
+                encode the current time window, so all codes generated within a window share them. Decode it with time_bucket_of().
+            time_secret: A secret keying the permutation of the time segment, so the segments of other windows cannot be
+                predicted from observed codes without the secret.
+            clock: A function returning the current unix time in seconds.
+            endianness: Whether the most significant character comes first ('big') or last ('little')
+                when codes are interpreted as numbers, see to_int() and from_int().
+            checkerboard: A boolean indicating whether the whole output alternates between two characters across
+                block boundaries (ABAB AB), instead of selecting a rule per block.
+            min_transitions: The minimum number of positions where consecutive characters differ, ignoring separators.
+                Strings with fewer transitions are regenerated.
+            forbidden_bigrams: An optional collection of two-character sequences (e.g. "00") that must not appear,
+                also across block boundaries. Strings containing them are regenerated.
+            case_ratio: An optional fraction in [0, 1] of letters that are uppercased; the others are lowercased.
+                Requires both lowercase and uppercase letters; numbers are not affected.
+            timestamp_block: An optional block index at which the unix time of generation is encoded,
+                spilling over into the following blocks if necessary. See is_expired().
+            checksum: Whether one character is replaced by a check character of all other characters: 'luhn' (or True)
+                for Luhn mod N, or 'iso7064' for ISO 7064 pure MOD N+1-2, e.g. MOD 37-2, which uses "*" as an
+                additional check character and requires N+1 to be prime. See validate().
+            checksum_position: The index of the check character among the significant characters; negative
+                indices count from the end. Defaults to the last character.
+            checksum_group: A boolean indicating whether a group is appended whose i-th character is the Luhn mod N check
+                character of the i-th block (A1B2C-3D4E5-...-K7W2Q), as in license keys. See verify_checksum_group().
+            markov_order: The number of preceding characters the Markov model conditions on.
+            markov_sample: An optional sample text. If provided, the characters fed into the rules follow the
+                character transitions of the sample (restricted to the character set) instead of a uniform choice.
+                Unknown contexts fall back to shorter ones and finally to a uniform choice.
+            non_monotonic: A boolean indicating whether strings whose significant characters are entirely sorted
+                in ascending or descending character set order (e.g. 0123 4589, or 7777) are regenerated.
+            sequence_key: An optional secret key for generate_sequence(), which issues codes for an incrementing
+                counter, scrambled by a keyed Feistel permutation. Recover the counter with sequence_of().
+            alternating_scripts: An optional pair of alphabets, e.g. ("ABCD", "αβγδ"). Even blocks are drawn from the
+                first, odd blocks from the second; the character set is their union. Encoded segments and check
+                characters may use the whole character set.
+            bloom_expected: An optional number of codes to size a Bloom filter of issued codes for. Candidates the filter
+                probably contains are regenerated, so codes are not repeated in bounded memory, at the cost of skipping
+                some never issued codes. The filter is available as the bloom attribute, e.g. to persist it.
+            bloom_false_positive_rate: The false positive rate of the Bloom filter.
+            modulus_checksum: An optional modulus M. The trailing characters encode the numeric value of all other
+                characters (see to_int()) modulo M, so the check is tied to the numeric meaning. See verify_modulus().
+            min_readability: The minimum readability score in [0, 1] of generated strings; lower scoring strings
+                are regenerated. See readability_score().
+            rules: An optional list of built-in rule names that are eligible, in this order, e.g. ['alternate', 'pairs'].
+                Defaults to all built-in rules. See use_rules() to include custom rules.
+            rule_selection: An optional rule name that every block uses, or a mapping of rule names to weights that bias
+                the rule selection, e.g. {'repeat': 7, 'pairs': 3}. Weights have to be positive and are normalized to
+                probabilities; leave rules out to exclude them. Defaults to a uniform choice among all rules.
+                See set_rule_selection().
+            secure: A boolean indicating whether all randomness is drawn from the operating system's cryptographically
+                secure entropy source, so codes are unpredictable, e.g. for access codes. This is considerably slower.
+                Methods deriving codes from an explicit seed, such as generate_fixtures(), stay deterministic.
+                Recipes are not available, see generate_with_recipe().
+            min_entropy: The minimum estimated entropy in bits of generated strings. Blocksizes and lengths
+                falling below it are refused. See entropy().
+            outliers: The number of outlier characters per block of the outlier rule, or a fraction of the blocksize
+                if it is a float, e.g. 0.25. It is limited so that every block has at least one outlier and one other character.
+            blocksize: An optional default blocksize for calls that omit it.
+            length: An optional default length for calls that omit it.
+        
+        Raises:
+            All errors are ConfigurationError (a ValueError), or its subclass EmptyCharacterSetError if no characters remain.
+            ValueError: If an option is unknown, e.g. misspelled.
+            ValueError: If none of the options (numbers, lowercase, uppercase, symbols) are set to True.
+            ValueError: If the custom alphabet is empty or contains entries that are not single characters.
+            ValueError: If the charset is unknown or combined with a custom alphabet.
+            ValueError: If reject_homoglyphs is set and the character set contains homoglyphs.
+            ValueError: If the font profile is unknown or leaves no characters.
+            ValueError: If excluding ambiguous or other characters leaves fewer than two characters.
+            ValueError: If max_char_frequency is not in (0, 1].
+            ValueError: If endianness is neither 'big' nor 'little'.
+            ValueError: If min_transitions is negative.
+            ValueError: If swap_probability is not in [0, 1].
+            ValueError: If grouping is not larger than zero.
+            ValueError: If min_readability is not in [0, 1].
+            ValueError: If the default blocksize or length is not larger than zero.
+            ValueError: If outliers is neither a positive count nor a fraction in (0, 1].
+            ValueError: If both a seed and a random source are given, or either is combined with secure.
+            ValueError: If the eligible rules are empty or refer to unknown rules.
+            ValueError: If the rule selection is empty, refers to unknown rules or has weights that are not positive finite numbers.
+            ValueError: If the allowlist contains empty codes.
+            ValueError: If the separator shares characters with the character set.
+            ValueError: If the padding character is not a single character outside the character set and separator,
+                or is combined with chained checksums.
+            ValueError: If the exact composition refers to a class without characters in the character set,
+                contains negative counts, or is combined with chained checksums or padding.
+            ValueError: If the shard identifier does not fit into shard_bits, or is combined with an exact composition
+                or chained checksums.
+            ValueError: If the block initials contain characters outside of the character set,
+                or are combined with an exact composition or a shard identifier.
+            ValueError: If interleave is not a pair of PrettyRandom instances, or is combined with an exact composition.
+            ValueError: If checkerboard is combined with an exact composition or interleaved generators.
+            ValueError: If a banned block is empty.
+            ValueError: If a forbidden bigram does not consist of exactly two characters.
+            ValueError: If the case ratio is not in [0, 1], the character set lacks lowercase or uppercase letters,
+                or it is combined with an exact composition.
+            ValueError: If the time bucket is not positive, or is combined with an exact composition or block initials.
+            ValueError: If the timestamp block is negative, or is combined with an exact composition, block initials
+                or chained checksums.
+            ValueError: If the error correction level is not 0, 1 or 2, or is combined with chained checksums,
+                padding or an exact composition.
+            ValueError: If the checksum algorithm is unknown, or ISO 7064 is used with an unsuitable character set.
+            ValueError: If the checksum is combined with chained checksums, error correction, padding or an exact composition.
+            ValueError: If the checksum group is used without a separator, or is combined with grouping, padding,
+                error correction or ISO 7064 check characters.
+            ValueError: If the Markov order is smaller than one or the sample contains no characters of the character set.
+            ValueError: If the alternating scripts are not a pair of alphabets of single characters, or are combined with
+                a custom alphabet, interleaved generators, an exact composition, checkerboard, a case ratio or a Markov sample.
+            ValueError: If the expected number of codes of the Bloom filter is not positive or its false positive rate is not in (0, 1).
+            ValueError: If the modulus is smaller than two, or is combined with chained checksums, a checksum,
+                error correction, padding or an exact composition.
+        """
+
+        # Define default values for keyword arguments
+        # By default, the character set includes numbers and uppercase letters only.
+        default_values: Dict[str, bool] = {
+            'use_numbers': True,
+            'use_lowercase': False,
+            'use_uppercase': True,
+            'use_symbols': False,
+            'exclude_ambiguous': False,
+            'exclude': None,
+            'characters': None,
+            'charset': None,
+            'reject_homoglyphs': False,
+            'max_char_frequency': 1.0,
+            'chained_checksums': False,
+            'allowlist': None,
+            'normalize_case': True,
+            'normalize_separators': True,
+            'separator': " ",
+            'grouping': None,
+            'padding_character': None,
+            'swap_probability': 0.5,
+            'seed': None,
+            'rng': None,
+            'exact_composition': None,
+            'shard_id': None,
+            'shard_bits': 0,
+            'block_initials': None,
+            'interleave': None,
+            'banned_blocks': None,
+            'error_correction': 0,
+            'font_profile': None,
+            'time_bucket': None,
+            'time_secret': "",
+            'clock': time.time,
+            'endianness': 'big',
+            'checkerboard': False,
+            'min_transitions': 0,
+            'forbidden_bigrams': None,
+            'case_ratio': None,
+            'timestamp_block': None,
+            'checksum': False,
+            'checksum_position': -1,
+            'checksum_group': False,
+            'markov_order': 1,
+            'markov_sample': None,
+            'non_monotonic': False,
+            'sequence_key': None,
+            'alternating_scripts': None,
+            'bloom_expected': None,
+            'bloom_false_positive_rate': 0.01,
+            'modulus_checksum': None,
+            'min_readability': 0.0,
+            'rules': None,
+            'rule_selection': None,
+            'secure': False,
+            'min_entropy': 0.0,
+            'outliers': 1,
+            'blocksize': None,
+            'length': None
+        }
+
+        # Merge default values with provided keyword arguments, rejecting misspelled options
+        unknown: List[str] = sorted(set(kwargs) - set(default_values))
+        if unknown:
+            raise ConfigurationError(f"Unknown options: {', '.join(unknown)}.")
+        config = {**default_values, **kwargs}
+        if config['characters'] is None and config['charset'] is None and not (config['use_numbers'] or config['use_lowercase'] or config['use_uppercase'] or config['use_symbols']):
+            raise EmptyCharacterSetError("At least one of the options has to be set to True.")
+        if not 0 < config['max_char_frequency'] <= 1:
+            raise ConfigurationError("The maximum character frequency must be in (0, 1].")
+        if config['min_transitions'] < 0:
+            raise ConfigurationError("The minimum number of transitions must not be negative.")
+        if config['endianness'] not in ('big', 'little'):
+            raise ConfigurationError("Endianness must be 'big' or 'little'.")
+        if not 0 <= config['swap_probability'] <= 1:
+            raise ConfigurationError("The swap probability must be in [0, 1].")
+        if config['grouping'] is not None and config['grouping'] <= 0:
+            raise ConfigurationError("Grouping must be larger than zero.")
+        if not 0 <= config['min_readability'] <= 1:
+            raise ConfigurationError("The minimum readability must be in [0, 1].")
+        if any(config[key] is not None and config[key] <= 0 for key in ('blocksize', 'length')):
+            raise ConfigurationError("The default blocksize and length must be larger than zero.")
+        if isinstance(config['outliers'], bool) or not (isinstance(config['outliers'], int) and config['outliers'] >= 1 or isinstance(config['outliers'], float) and 0 < config['outliers'] <= 1):
+            raise ConfigurationError("Outliers must be a positive count or a fraction in (0, 1].")
+        self.config: Dict = config
+
+        # Guards state that changes during generation, see the class documentation
+        self.lock: threading.RLock = threading.RLock()
+
+        # Per-thread overrides of the ThreadLocal attributes, see overridden()
+        self.overrides: threading.local = threading.local()
+
+        # Rules and characters of the rendered blocks while a recipe is generated or replayed, otherwise None
+        self.trace: Optional[List[Tuple[str, str, str]]] = None
+
+        # Whether seeded() is active, e.g. to replay a recipe, so that stateful checks such as the Bloom filter are skipped
+        self.replaying: bool = False
+
+        # Counter of the next code issued by generate_sequence()
+        self.sequence: int = 0
+
+        # Filter of issued codes, replaceable by a restored one
+        self.bloom: Optional[BloomFilter] = None
+        if config['bloom_expected'] is not None:
+            self.bloom = BloomFilter(config['bloom_expected'], config['bloom_false_positive_rate'])
+
+        # Source of all randomness of this instance
+        if config['rng'] is not None and config['seed'] is not None:
+            raise ConfigurationError("A seed cannot be combined with a random source.")
+        if config['secure'] and (config['rng'] is not None or config['seed'] is not None):
+            raise ConfigurationError("A secure generator cannot be combined with a seed or random source.")
+        self.rng: random.Random = config['rng'] if config['rng'] is not None else random.Random(config['seed'])
+        if config['secure']: self.rng = SecureRandom()
+
+        # Remaining codes of the allowlist, without duplicates
+        self.allowlist: Optional[List[str]] = None
+        if config['allowlist'] is not None:
+            self.allowlist = list(dict.fromkeys(code.replace(config['separator'], "") if config['separator'] else code for code in config['allowlist']))
+            if "" in self.allowlist:
+                raise ConfigurationError("The allowlist must not contain empty codes.")
+
+        # Available pattern generation rules
+        self.rules: Dict[str, BoundRule] = {
+            'repeat': self.repeat,
+            'alternate': self.alternate,
+            'pairs': self.pairs,
+            'outlier': self.outlier,
+            'zerofill': self.zerofill,
+            'base64ish': self.base64ish,
+            'arch': self.arch,
+            'syllable': self.syllable
+        }
+
+        # Members and weights of registered composite rules
+        self.composites: Dict[str, Dict[str, int]] = {}
+
+        # Normalized weights of the rule selection, or None for a uniform choice
+        self.rule_weights: Optional[Dict[str, float]] = None
+
+        # Rules that are only replaced with overwrite=True, see register_rule()
+        self.builtin_rules: set[str] = set(self.rules)
+
+        # Names of the registered rules eligible for the rule selection, see use_rules()
+        self.eligible: List[str] = list(self.rules)
+        if config['rules'] is not None: self.use_rules(*config['rules'])
+        self.set_rule_selection(config['rule_selection'])
+
+        # Initialize sets
+        self.numbers: set[str] = {'0', '1', '2', '3', '4', '5', '6', '7', '8', '9'}
+        self.lowercase: set[str] = {'a', 'b', 'c', 'd', 'e', 'f', 'g', 'h', 'i', 'j', 'k', 'l', 'm', 'n', 'o', 'p', 'q', 'r', 's', 't', 'u', 'v', 'w', 'x', 'y', 'z'}
+        self.uppercase: set[str] = {'A', 'B', 'C', 'D', 'E', 'F', 'G', 'H', 'I', 'J', 'K', 'L', 'M', 'N', 'O', 'P', 'Q', 'R', 'S', 'T', 'U', 'V', 'W', 'X', 'Y', 'Z'}
+        self.symbols: set[str] = {'!', '@', '#', '$', '%', '^', '&', '*'}
+
+        # Use set operations to construct the character set, unless a custom alphabet or interleaved generators are given
+        if config['interleave'] is not None:
+            if len(config['interleave']) != 2 or not all(isinstance(stream, PrettyRandom) for stream in config['interleave']):
+                raise ConfigurationError("Interleave must be a pair of PrettyRandom instances.")
+            if config['exact_composition'] is not None:
+                raise ConfigurationError("Interleaved generators cannot be combined with an exact composition.")
+            characters: set[str] = set(config['interleave'][0].character_set) | set(config['interleave'][1].character_set)
+        elif config['alternating_scripts'] is not None:
+            if len(config['alternating_scripts']) != 2 or config['characters'] is not None:
+                raise ConfigurationError("Alternating scripts must be a pair of alphabets and cannot be combined with a custom alphabet.")
+            characters = set(config['alternating_scripts'][0]) | set(config['alternating_scripts'][1])
+            if any(len(char) != 1 for char in characters):
+                raise ConfigurationError("The alternating scripts must only contain single characters.")
+        elif config['charset'] is not None:
+            if config['charset'] not in CHARSETS or config['characters'] is not None:
+                raise ConfigurationError(f"Unknown charset {config['charset']!r}, or combined with a custom alphabet.")
+            characters = set(CHARSETS[config['charset']])
+        elif config['characters'] is not None:
+            characters = set(config['characters'])
+            if not characters:
+                raise EmptyCharacterSetError("The custom character set must not be empty.")
+            if any(len(char) != 1 for char in characters):
+                raise ConfigurationError("The custom character set must only contain single characters.")
+        else:
+            characters = (
+                (self.numbers if config['use_numbers'] else set()) |
+                (self.lowercase if config['use_lowercase'] else set()) |
+                (self.uppercase if config['use_uppercase'] else set()) |
+                (self.symbols if config['use_symbols'] else set())
+            )
+
+        # Sort the character set so its order does not depend on set iteration
+        self.character_set: List[str] = self.remove_homoglyphs(sorted(characters), strict=config['reject_homoglyphs'])
+
+        # Restrict the character set to characters the display can render
+        if config['font_profile'] is not None:
+            if config['font_profile'] not in FONT_PROFILES:
+                raise ConfigurationError(f"Unknown font profile {config['font_profile']!r}.")
+            self.character_set = [char for char in self.character_set if char in FONT_PROFILES[config['font_profile']]]
+            if not self.character_set:
+                raise EmptyCharacterSetError("The font profile leaves no characters in the character set.")
+
+        # Two-character rules need at least two characters to remain
+        excluded: set[str] = (AMBIGUOUS_CHARACTERS if config['exclude_ambiguous'] else set()) | set(config['exclude'] or [])
+        if excluded:
+            self.character_set = [char for char in self.character_set if char not in excluded]
+            if not self.character_set:
+                raise EmptyCharacterSetError("Excluding characters leaves no characters in the character set.")
+            if len(self.character_set) < 2:
+                raise ConfigurationError("Excluding characters must leave at least two characters in the character set.")
+
+        # Blocks must stay distinguishable from the separator
+        self.separator: str = config['separator']
+        if set(self.separator) & set(self.character_set):
+            raise ConfigurationError("The separator must not contain characters of the character set.")
+
+        padding: Optional[str] = config['padding_character']
+        if padding is not None:
+            if len(padding) != 1 or padding in self.character_set or padding in self.separator:
+                raise ConfigurationError("The padding character must be a single character outside the character set and separator.")
+            if config['chained_checksums']:
+                raise ConfigurationError("Padding cannot be combined with chained checksums.")
+
+        # Characters of the character set by class
+        self.classes: Dict[str, List[str]] = {
+            name: [char for char in self.character_set if char in members]
+            for name, members in [('numbers', self.numbers), ('lowercase', self.lowercase), ('uppercase', self.uppercase), ('symbols', self.symbols)]
+        }
+
+        composition: Optional[Dict[str, int]] = config['exact_composition']
+        if composition is not None:
+            for name, count in composition.items():
+                if not self.classes.get(name):
+                    raise ConfigurationError(f"The character class {name!r} is not enabled.")
+                if count < 0:
+                    raise ConfigurationError("Character class counts must not be negative.")
+            if config['chained_checksums'] or padding is not None:
+                raise ConfigurationError("An exact composition cannot be combined with chained checksums or padding.")
+
+        # Number of leading characters reserved for the shard identifier
+        self.shard_width: int = 0
+        if config['shard_id'] is not None:
+            if config['shard_bits'] <= 0 or not 0 <= config['shard_id'] < 2 ** config['shard_bits']:
+                raise ConfigurationError("The shard identifier must fit into shard_bits bits.")
+            if composition is not None or config['chained_checksums']:
+                raise ConfigurationError("A shard identifier cannot be combined with an exact composition or chained checksums.")
+            while len(self.character_set) ** self.shard_width < 2 ** config['shard_bits']:
+                self.shard_width += 1
+
+        self.banned_blocks: set[str] = set(config['banned_blocks'] or [])
+        if "" in self.banned_blocks:
+            raise ConfigurationError("Banned blocks must not be empty.")
+
+        # Number of characters encoding the time bucket, enough for 2^32 windows
+        self.time_width: int = 0
+        if config['time_bucket'] is not None:
+            if config['time_bucket'] <= datetime.timedelta(0):
+                raise ConfigurationError("The time bucket must be positive.")
+            if composition is not None or config['block_initials'] is not None or config['chained_checksums']:
+                raise ConfigurationError("A time bucket cannot be combined with an exact composition, block initials or chained checksums.")
+            while len(self.character_set) ** self.time_width < 2 ** 32:
+                self.time_width += 1
+
+        if config['checkerboard'] and (composition is not None or config['interleave'] is not None):
+            raise ConfigurationError("Checkerboard cannot be combined with an exact composition or interleaved generators.")
+
+        # Number of characters encoding the unix time, enough for 2^34 seconds
+        self.timestamp_width: int = 0
+        if config['timestamp_block'] is not None:
+            if config['timestamp_block'] < 0:
+                raise ConfigurationError("The timestamp block must not be negative.")
+            if composition is not None or config['block_initials'] is not None or config['chained_checksums']:
+                raise ConfigurationError("A timestamp cannot be combined with an exact composition, block initials or chained checksums.")
+            while len(self.character_set) ** self.timestamp_width < 2 ** 34:
+                self.timestamp_width += 1
+
+        if config['error_correction'] not in (0, 1, 2):
+            raise ConfigurationError("The error correction level must be 0, 1 or 2.")
+        if config['error_correction'] and (config['chained_checksums'] or padding is not None or composition is not None):
+            raise ConfigurationError("Error correction cannot be combined with chained checksums, padding or an exact composition.")
+        if config['error_correction'] == 2 and not self.is_prime(len(self.character_set)):
+            raise ConfigurationError(f"Correcting errors requires a character set of prime size, not {len(self.character_set)} characters.")
+
+        self.forbidden_bigrams: set[str] = set(config['forbidden_bigrams'] or [])
+        if any(len(bigram) != 2 for bigram in self.forbidden_bigrams):
+            raise ConfigurationError("Forbidden bigrams must consist of exactly two characters.")
+
+        if config['case_ratio'] is not None:
+            if not 0 <= config['case_ratio'] <= 1:
+                raise ConfigurationError("The case ratio must be in [0, 1].")
+            if not (self.classes['lowercase'] and self.classes['uppercase']):
+                raise ConfigurationError("A case ratio requires both lowercase and uppercase letters.")
+            if composition is not None:
+                raise ConfigurationError("A case ratio cannot be combined with an exact composition.")
+
+        if config['checksum'] not in (False, True, 'luhn', 'iso7064'):
+            raise ConfigurationError(f"Unknown checksum algorithm {config['checksum']!r}.")
+        if config['checksum'] == 'iso7064':
+            modulus: int = len(self.character_set) + 1
+            if any(modulus % i == 0 for i in range(2, math.isqrt(modulus) + 1)) or "*" in self.character_set or "*" in self.separator:
+                raise ConfigurationError("ISO 7064 MOD N+1-2 requires N+1 to be prime and '*' to be outside the character set and separator.")
+        if config['checksum'] and (config['chained_checksums'] or config['error_correction'] or padding is not None or composition is not None):
+            raise ConfigurationError("A checksum cannot be combined with chained checksums, error correction, padding or an exact composition.")
+        if config['checksum_group'] and (not config['separator'] or config['grouping'] or padding is not None or config['error_correction']):
+            raise ConfigurationError("A checksum group requires a separator and cannot be combined with grouping, padding or error correction.")
+        if config['checksum_group'] and config['checksum'] == 'iso7064':
+            # The supplementary check character "*" is outside the alphabet of the Luhn check group
+            raise ConfigurationError("A checksum group cannot be combined with ISO 7064 check characters.")
+
+        # Counts of the characters following each context of up to markov_order characters in the sample
+        self.markov_model: Dict[str, Counter] = {}
+        if config['markov_sample'] is not None:
+            if config['markov_order'] < 1:
+                raise ConfigurationError("The Markov order must be at least one.")
+            sample: str = "".join(char for char in config['markov_sample'] if char in self.character_set)
+            if not sample:
+                raise ConfigurationError("The Markov sample must contain characters of the character set.")
+            for i, char in enumerate(sample):
+                for order in range(min(config['markov_order'], i) + 1):
+                    self.markov_model.setdefault(sample[i - order:i], Counter())[char] += 1
+
+        # Number of trailing characters encoding the modulus checksum
+        self.modulus_width: int = 0
+        if config['modulus_checksum'] is not None:
+            if config['modulus_checksum'] < 2:
+                raise ConfigurationError("The modulus must be at least two.")
+            if config['chained_checksums'] or config['checksum'] or config['error_correction'] or padding is not None or composition is not None:
+                raise ConfigurationError("A modulus checksum cannot be combined with chained checksums, a checksum, error correction, padding or an exact composition.")
+            while len(self.character_set) ** self.modulus_width < config['modulus_checksum']:
+                self.modulus_width += 1
+
+        # Alphabets of even and odd blocks, restricted to the character set
+        self.scripts: Optional[Tuple[List[str], List[str]]] = None
+        if config['alternating_scripts'] is not None:
+            self.scripts = tuple([char for char in self.character_set if char in script] for script in config['alternating_scripts'])
+            if not all(self.scripts):
+                raise ConfigurationError("Each alternating script must contain characters of the character set.")
+            if composition is not None or config['checkerboard'] or config['case_ratio'] is not None or config['markov_sample'] is not None:
+                raise ConfigurationError("Alternating scripts cannot be combined with an exact composition, checkerboard, a case ratio or a Markov sample.")
+
+        initials: Optional[str] = config['block_initials']
+        if initials is not None:
+            if not initials or not set(initials) <= set(self.character_set):
+                raise ConfigurationError("The block initials must be non-empty and only contain characters of the character set.")
+            if composition is not None or config['shard_id'] is not None:
+                raise ConfigurationError("Block initials cannot be combined with an exact composition or a shard identifier.")
+
+
+    @staticmethod
+    def remove_homoglyphs(characters: List[str], strict: bool = False) -> List[str]:
+        """
+        Removes characters that look identical to another character of the set (Latin 'A' vs Cyrillic 'А').
+        Of each group of lookalikes, the Latin character is kept if present, otherwise the first one.
+
+        Args:
+            characters: The characters to de-conflict.
+            strict: If True, raise an error instead of dropping homoglyphs.
+
+        Returns:
+            The characters without homoglyphs, in their original order.
+
+        Raises:
+            ValueError: If strict is set and the characters contain homoglyphs.
+        """
+        groups: Dict[str, List[str]] = {}
+        for char in characters:
+            groups.setdefault(HOMOGLYPHS.get(char, char), []).append(char)
+
+        conflicts: List[List[str]] = [group for group in groups.values() if len(group) > 1]
+        if strict and conflicts:
+            raise ConfigurationError(f"The character set contains homoglyphs: {conflicts}")
+
+        kept: set[str] = {canonical if canonical in group else group[0] for canonical, group in groups.items()}
+        return [char for char in characters if char in kept]
+
+
+    def repeat(self, char1: str, char2: str, blocksize: int) -> str:
+        """
+        Generates a repeated pattern of the first character (AAAA).
+
+        Args:
+            char1: The character to be repeated.
+            char2: Unused; kept so that all rules share the same signature.
+            blocksize: The desired size of the block or pattern.
+
+        Returns:
+            A string representing the generated repeated pattern.
+        """
+        return str(char1) * blocksize
+    
+
+    def alternate(self, char1: str, char2: str, blocksize: int) -> str:
+        """
+        Generates an alternating pattern of characters (ABAB).
+
+        Args:
+            char1: The first character to be used in the pattern.
+            char2: The second character to be used in the pattern.
+            blocksize: The desired size of the block or pattern.
+
+        Returns:
+            A string representing the generated alternating pattern.
+        """
+        return "".join([str(char1) if i % 2 == 0 else str(char2) for i in range(blocksize)])
+    
+
+    def pairs(self, char1: str, char2: str, blocksize: int) -> str:
+        """
+        Generates a pattern of repeating pairs of characters, switching between char1 and char2 (AABB AABB).
+
+        Args:
+            char1: The first character to be used in the pattern.
+            char2: The second character to be used in the pattern.
+            blocksize: The desired size of the block or pattern.
+
+        Returns:
+            A string representing the generated pattern of repeating character pairs.
+        """
+        block: str = (str(char1) * 2 + str(char2) * 2) * (blocksize // 4 + 1)
+        return block[:blocksize]
+    
+
+    def outlier(self, char1: str, char2: str, blocksize: int) -> str:
+        """
+        Generates a pattern with outlier characters (char2) randomly placed within char1 characters (AABA).
+        The number of outliers is set by the outliers option.
+
+        Args:
+            char1: The character to be used as the majority in the pattern.
+            char2: The character to be used as the outlier in the pattern.
+            blocksize: The desired size of the block or pattern.
+
+        Returns:
+            A string representing the generated pattern with outlier characters.
+        """
+        block: List[str] = [str(char1)] * blocksize
+        for position in self.rng.sample(range(blocksize), self.outlier_count(blocksize)):
+            block[position] = str(char2)
+        return "".join(block)
+
+
+    def outlier_count(self, blocksize: int) -> int:
+        """
+        Returns the number of outliers in a block of the outlier rule: the outliers option if it is a count,
+        or that fraction of the blocksize, rounded, if it is a ratio. There is at least one outlier and,
+        in blocks of two or more characters, at least one majority character.
+        """
+        outliers: Union[int, float] = self.config['outliers']
+        count: int = outliers if isinstance(outliers, int) else round(outliers * blocksize)
+        return max(1, min(count, blocksize - 1))
+    
+
+    def zerofill(self, char1: str, char2: str, blocksize: int) -> str:
+        """
+        Generates a run of the second character of random length, padded with the first character
+        to the blocksize on the left or the right (000A, AAXX or XX00).
+
+        Args:
+            char1: The character to pad with.
+            char2: The character of the run.
+            blocksize: The desired size of the block or pattern.
+
+        Returns:
+            A string representing the generated padded pattern.
+        """
+        # Blocks of two or more characters keep at least one padding character
+        run: int = self.rng.randint(1, max(1, blocksize - 1))
+        padding: str = str(char1) * (blocksize - run)
+        return padding + str(char2) * run if self.rng.random() < 0.5 else str(char2) * run + padding
+    
+
+    def base64ish(self, char1: str, char2: str, blocksize: int) -> str:
+        """
+        Generates a block of characters drawn independently from the character set, resembling base64 (aZ3q).
+        This is purely decorative and not actual base64.
+
+        Args:
+            char1: The first character of the block.
+            char2: The second character of the block.
+            blocksize: The desired size of the block or pattern.
+
+        Returns:
+            A string representing the generated base64-like block.
+        """
+        block: str = str(char1) + str(char2) + "".join(self.rng.choice(self.character_set) for _ in range(blocksize - 2))
+        return block[:blocksize]
+    
+
+    def arch(self, char1: str, char2: str, blocksize: int) -> str:
+        """
+        Generates a pattern that rises from the first character in steps of two through the character set
+        up to a random peak and falls back down on the other parity, wrapping around the set (ACEDB).
+
+        Args:
+            char1: The character at the foot of the arch.
+            char2: Unused; kept so that all rules share the same signature.
+            blocksize: The desired size of the block or pattern.
+
+        Returns:
+            A string representing the generated arch pattern.
+        """
+        start: int = self.character_set.index(char1)
+        peak: int = self.rng.randint(1, blocksize - 2) if blocksize >= 3 else blocksize - 1
+        indices: List[int] = [start + 2 * i if i <= peak else start + 2 * peak - 2 * (i - peak) + 1 for i in range(blocksize)]
+        return "".join(self.character_set[i % len(self.character_set)] for i in indices)
+    
+
+    def syllables(self, char: str) -> Tuple[List[str], List[str]]:
+        """
+        Partitions the letters of the character set into consonants and vowels (a, e, i, o, u).
+        If both cases are available, the letters of the same case as char are preferred.
+        """
+        letters: List[str] = [c for c in self.character_set if c.isalpha()]
+        same_case: List[str] = [c for c in letters if c.isupper() == char.isupper()]
+        for pool in (same_case, letters):
+            vowels: List[str] = [c for c in pool if c.lower() in "aeiou"]
+            consonants: List[str] = [c for c in pool if c.lower() not in "aeiou"]
+            if vowels and consonants: return consonants, vowels
+        return [], []
+
+
+    def syllable(self, char1: str, char2: str, blocksize: int) -> str:
+        """
+        Generates a pronounceable pattern of consonant-vowel syllables (BAKO), starting with char1 and char2
+        where they are a consonant and a vowel. Falls back to the alternate pattern if the character set
+        lacks consonants or vowels, e.g. without letters.
+
+        Args:
+            char1: The first consonant of the pattern.
+            char2: The first vowel of the pattern.
+            blocksize: The desired size of the block or pattern.
+
+        Returns:
+            A string representing the generated syllable pattern.
+        """
+        consonants, vowels = self.syllables(char1)
+        if not consonants:
+            return self.alternate(char1, char2, blocksize)
+        block: List[str] = [self.rng.choice(vowels if i % 2 else consonants) for i in range(blocksize)]
+        if char1 in consonants: block[0] = char1
+        if char2 in vowels and blocksize > 1: block[1] = char2
+        return "".join(block)
+    
+
+    def random_rule(self) -> BoundRule:
+        """
+        Randomly selects a rule function from the eligible rules, following the rule selection if one is set.
+        """
+        if self.rule_weights is not None:
+            return self.rules[self.rng.choices(list(self.rule_weights.keys()), weights=list(self.rule_weights.values()))[0]]
+        return self.rules[self.rng.choice(self.eligible)]
+
+
+    def set_rule_selection(self, selection: Optional[Union[str, Dict[str, float]]]) -> None:
+        """
+        Sets which rules are selected for the blocks, e.g. after registering custom rules.
+
+        Args:
+            selection: A rule name that every block uses, a mapping of rule names to weights that are normalized
+                to probabilities, or None for a uniform choice among all eligible rules.
+
+        Raises:
+            ValueError: If the selection is empty, refers to unknown or ineligible rules, or has weights that are not positive finite numbers.
+        """
+        weights: Optional[Dict[str, float]] = {selection: 1} if isinstance(selection, str) else selection
+        if weights is not None:
+            if not weights:
+                raise ConfigurationError("The rule selection must contain at least one rule.")
+            for name, weight in weights.items():
+                if name not in self.eligible:
+                    raise ConfigurationError(f"Unknown or ineligible rule {name!r}.")
+                if isinstance(weight, bool) or not isinstance(weight, (int, float)) or not 0 < weight < math.inf:
+                    raise ConfigurationError(f"The weight of rule {name!r} must be a positive finite number, not {weight!r}.")
+            total: float = sum(weights.values())
+            weights = {name: weight / total for name, weight in weights.items()}
+        self.rule_weights = weights
+        self.config['rule_selection'] = selection
+
+
+    def register_rule(self, name: str, rule: Rule, overwrite: bool = False) -> None:
+        """
+        Registers a custom rule, making it eligible for the rule selection.
+        A rule receives the random source of the generator, two characters and the blocksize,
+        and has to return exactly blocksize characters.
+
+        Args:
+            name: The name of the rule.
+            rule: The rule function, e.g. lambda rng, char1, char2, blocksize: "".join(rng.choice((char1, char2)) for _ in range(blocksize)).
+            overwrite: A boolean indicating whether an existing rule of the same name, including a built-in one, may be replaced.
+
+        Raises:
+            ValueError: If the name is empty, or already registered and overwrite is not set.
+        """
+        if not name:
+            raise ConfigurationError("Rule names must not be empty.")
+        if name in self.rules and not overwrite:
+            kind: str = "built-in" if name in self.builtin_rules else "registered"
+            raise ConfigurationError(f"The rule {name!r} is already {kind}; pass overwrite=True to replace it.")
+
+        # Like composites, a partial of a bound method follows this instance into clones and worker processes
+        self.rules[name] = functools.partial(self.custom_rule, rule)
+        self.composites.pop(name, None)
+        if name not in self.eligible: self.eligible.append(name)
+
+
+    def custom_rule(self, rule: Rule, char1: str, char2: str, blocksize: int) -> str:
+        """
+        Renders a block with a registered rule, passing it the random source of this instance. See register_rule().
+        """
+        return rule(self.rng, char1, char2, blocksize)
+
+
+    def rule_names(self) -> List[str]:
+        """
+        Returns the names of all rules eligible for the rule selection, in registration order or the order given to use_rules().
+        """
+        return list(self.eligible)
+
+
+    def use_rules(self, *names: str) -> None:
+        """
+        Restricts the eligible rules to the given built-in or registered rules, in the given order.
+        Rules that are left out stay registered and can be made eligible again; a rule selection referring to them is reset.
+
+        Args:
+            names: The names of the eligible rules.
+
+        Raises:
+            ValueError: If no names are given or a name refers to an unknown rule.
+        """
+        if not names:
+            raise ConfigurationError("At least one rule has to be eligible.")
+        for name in names:
+            if name not in self.rules:
+                raise ConfigurationError(f"Unknown rule {name!r}.")
+        self.eligible = list(dict.fromkeys(names))
+        if self.rule_weights is not None and not set(self.rule_weights) <= set(self.eligible): self.set_rule_selection(None)
+
+
+    def register_composite(self, name: str, members: Dict[str, int]) -> None:
+        """
+        Registers a rule that delegates every block to one of several existing rules, chosen by weight.
+        The composite participates in the rule selection as a single rule.
+
+        Args:
+            name: The name of the composite rule, e.g. "fancy".
+            members: A mapping of existing rule names to positive integer weights, e.g. {'alternate': 3, 'pairs': 1}.
+
+        Raises:
+            ValueError: If the name is empty or already taken, or the members are empty,
+                refer to unknown rules or have non-positive weights.
+        """
+        if not name or name in self.rules:
+            raise ConfigurationError(f"Invalid rule name {name!r}: names must be non-empty and not already registered.")
+        if not members:
+            raise ConfigurationError("A composite rule needs at least one member.")
+        for member, weight in members.items():
+            if member not in self.rules:
+                raise ConfigurationError(f"Unknown rule {member!r}.")
+            if weight <= 0:
+                raise ConfigurationError("Member weights must be positive.")
+
+        # A partial of a bound method, unlike a closure, follows this instance into clones and worker processes
+        rules: List[BoundRule] = [self.rules[member] for member in members]
+        self.rules[name] = functools.partial(self.composite, rules, list(members.values()))
+        self.composites[name] = dict(members)
+        self.eligible.append(name)
+
+
+    def composite(self, rules: List[BoundRule], weights: List[int], char1: str, char2: str, blocksize: int) -> str:
+        """
+        Renders a block with one of several rules, chosen by weight. See register_composite().
+        """
+        return self.rng.choices(rules, weights=weights)[0](char1, char2, blocksize)
+
+
+    def apply_rule(self, rule: BoundRule, char1: str, char2: str, blocksize: int) -> str:
+        """
+        Applies a rule to two characters, first swapping them with the configured swap probability
+        so that either character may end up as the primary one.
+
+        Args:
+            rule: The rule function to apply.
+            char1: The first character to be used in the pattern.
+            char2: The second character to be used in the pattern.
+            blocksize: The desired size of the block or pattern.
+
+        Returns:
+            The block generated by the rule.
+
+        Raises:
+            ValueError: If the rule does not return a string of exactly blocksize characters.
+        """
+        if self.rng.random() < self.config['swap_probability']: char1, char2 = char2, char1
+        block: str = rule(char1, char2, blocksize)
+        if not isinstance(block, str) or len(block) != blocksize:
+            raise ConfigurationError(f"The rule {getattr(rule, '__name__', rule)!r} returned {block!r} instead of {blocksize} characters.")
+        return block
+
+
+    def generate_blocks(self, blocksize: int, length: int) -> List[str]:
+        """
+        Generates the blocks of a single candidate string, without checking the configured constraints.
+
+        Args:
+            blocksize: The size of each block or pattern within the string.
+            length: The desired length of the generated string.
+
+        Returns:
+            A list of blocks; all but the last have exactly blocksize characters.
+        """
+        num_blocks: int = length // blocksize
+        rest: int = length % blocksize
+        if self.trace is not None: self.trace.clear()
+
+        composition: Optional[Dict[str, int]] = self.config['exact_composition']
+        interleave: Optional[Tuple[PrettyRandom, PrettyRandom]] = self.config['interleave']
+        blocks: List[str]
+        if composition is not None:
+            # Shuffle the exact number of characters per class instead of applying rules
+            chars: List[str] = [self.rng.choice(self.classes[name]) for name, count in composition.items() for _ in range(count)]
+            self.rng.shuffle(chars)
+            blocks = ["".join(chars[i:i + blocksize]) for i in range(0, length, blocksize)]
+        elif interleave is not None:
+            # Take even positions from the first and odd positions from the second generator
+            first, second = interleave
+            chars = [""] * length
+            chars[0::2] = first.significant(first(min(blocksize, (length + 1) // 2), (length + 1) // 2))
+            if length > 1: chars[1::2] = second.significant(second(min(blocksize, length // 2), length // 2))
+            blocks = ["".join(chars[i:i + blocksize]) for i in range(0, length, blocksize)]
+        elif self.config['checkerboard']:
+            # Alternate two distinct characters across the whole output, ignoring block boundaries
+            even: str = self.rng.choice(self.character_set)
+            odd: str = self.rng.choice([char for char in self.character_set if char != even] or [even])
+            chars = list(self.alternate(even, odd, length))
+            blocks = ["".join(chars[i:i + blocksize]) for i in range(0, length, blocksize)]
+        else:
+            # Generate complete blocks
+            blocks = []
+            for i in range(num_blocks):
+                with self.restricted(self.scripts[i % 2] if self.scripts else self.character_set):
+                    blocks.append(self.render_block(self.random_rule, blocksize, "".join(blocks)))
+
+            # Fill up remaining characters with alternate pattern
+            if rest != 0:
+                with self.restricted(self.scripts[num_blocks % 2] if self.scripts else self.character_set):
+                    blocks.append(self.render_block(lambda: self.alternate, rest, "".join(blocks)))
+
+        if self.config['case_ratio'] is not None: blocks = [self.recase(block) for block in blocks]
+        if self.config['block_initials'] is not None: blocks = [initial + block[1:] for initial, block in zip(self.config['block_initials'], blocks)]
+        if self.config['shard_id'] is not None: blocks = self.overwrite(blocks, 0, self.encode_int(self.config['shard_id'], self.shard_width))
+        if self.config['time_bucket'] is not None: blocks = self.overwrite(blocks, self.shard_width, self.time_segment(self.current_time_bucket()))
+        if self.config['timestamp_block'] is not None:
+            timestamp: str = self.encode_int(int(self.config['clock']()), self.timestamp_width)
+            blocks = self.overwrite(blocks, self.config['timestamp_block'] * blocksize, timestamp)
+        if self.config['chained_checksums']: blocks = self.chain_checksums(blocks)
+        if self.config['checksum']: blocks = self.overwrite(blocks, self.config['checksum_position'] % length, self.check_character("".join(blocks)))
+        if self.config['error_correction']:
+            level: int = self.config['error_correction']
+            chars = "".join(blocks)[:-level]
+            blocks = self.overwrite(blocks, length - level, self.error_correction_characters(chars, level))
+        if self.config['modulus_checksum'] is not None:
+            value: int = self.decode_int("".join(blocks)[:-self.modulus_width])
+            blocks = self.overwrite(blocks, length - self.modulus_width, self.encode_int(value % self.config['modulus_checksum'], self.modulus_width))
+        if self.config['padding_character'] is not None: blocks[-1] = self.pad_block(blocks[-1])
+        return blocks
+
+
+    def recase(self, block: str) -> str:
+        """
+        Uppercases each letter of a block with the probability given by the case ratio and lowercases it otherwise.
+        Letters whose other case is not part of the character set are kept.
+        """
+        recased: List[str] = []
+        for char in block:
+            other: str = char.lower() if char.isupper() else char.upper()
+            if other != char and other in self.character_set:
+                char = char.upper() if self.rng.random() < self.config['case_ratio'] else char.lower()
+            recased.append(char)
+        return "".join(recased)
+
+
+    def render_block(self, select_rule: Callable[[], BoundRule], blocksize: int, history: str = "") -> str:
+        """
+        Renders a single block with a rule and two random characters, re-rolling banned blocks.
+
+        Args:
+            select_rule: A function returning the rule to apply, called again on every re-roll.
+            blocksize: The desired size of the block.
+            history: The characters generated before this block, used by the Markov model.
+
+        Returns:
+            The rendered block.
+
+        Raises:
+            RuntimeError: If only banned blocks were rendered within max_attempts attempts.
+        """
+        for _ in range(self.max_attempts):
+            char1: str = self.choose_character(history)
+            char2: str = self.choose_character(history + char1)
+            rule: BoundRule = select_rule()
+            block: str = self.sanitize_block(self.apply_rule(rule, char1, char2, blocksize))
+            if block in self.banned_blocks: continue
+            if self.trace is not None:
+                name: str = next((name for name, candidate in self.rules.items() if candidate == rule), getattr(rule, '__name__', repr(rule)))
+                self.trace.append((name, char1, char2))
+            return block
+        raise GenerationError(f"Could not render a block that is not banned within {self.max_attempts} attempts.")
+
+
+    def choose_character(self, history: str) -> str:
+        """
+        Chooses a character of the character set. With a Markov model, the character follows the frequencies
+        of the sample after the longest known context at the end of history; otherwise it is chosen uniformly.
+        Followers outside the current character set, e.g. within restricted(), are skipped.
+        """
+        if self.markov_model:
+            for order in range(min(self.config['markov_order'], len(history)), -1, -1):
+                counts: Counter = self.markov_model.get(history[len(history) - order:]) or Counter()
+                followers: Dict[str, int] = {char: count for char, count in counts.items() if char in self.character_set}
+                if followers:
+                    return self.rng.choices(list(followers.keys()), weights=list(followers.values()))[0]
+        return self.rng.choice(self.character_set)
+
+
+    def sanitize_block(self, block: str) -> str:
+        """
+        Replaces whitespace and separator characters within a block, e.g. produced by a custom rule,
+        with random characters of the character set. This keeps the block structure unambiguous
+        without changing the length of the block.
+        """
+        return "".join(self.rng.choice(self.character_set) if char.isspace() or char in self.separator else char for char in block)
+
+
+    def overwrite(self, blocks: List[str], position: int, text: str) -> List[str]:
+        """
+        Overwrites the characters of the blocks starting at the given position, keeping the block layout.
+
+        Args:
+            blocks: The blocks to overwrite.
+            position: The index of the first character to overwrite, counted over all blocks.
+            text: The characters to write.
+
+        Returns:
+            The blocks with the characters replaced.
+        """
+        chars: List[str] = list("".join(blocks))
+        chars[position:position + len(text)] = text
+        result: List[str] = []
+        for block in blocks:
+            result.append("".join(chars[:len(block)]))
+            chars = chars[len(block):]
+        return result
+
+
+    def encode_int(self, value: int, width: int) -> str:
+        """
+        Encodes a non-negative integer in base N over the character set, using exactly width characters
+        in the configured endianness.
+
+        Raises:
+            ValueError: If the value is negative or does not fit into width characters.
+        """
+        n: int = len(self.character_set)
+        if value < 0 or value >= n ** width:
+            raise ValueError(f"The value {value} cannot be encoded in {width} characters.")
+        digits: List[str] = []
+        for _ in range(width):
+            value, digit = divmod(value, n)
+            digits.append(self.character_set[digit])
+        return "".join(reversed(digits) if self.config['endianness'] == 'big' else digits)
+
+
+    def decode_int(self, chars: str) -> int:
+        """
+        Decodes characters encoded with encode_int() back into an integer.
+
+        Raises:
+            ValueError: If a character is not part of the character set.
+        """
+        value: int = 0
+        for char in (chars if self.config['endianness'] == 'big' else reversed(chars)):
+            if char not in self.character_set:
+                raise ValueError(f"Character {char!r} is not part of the character set.")
+            value = value * len(self.character_set) + self.character_set.index(char)
+        return value
+
+
+    def to_int(self, code: str) -> int:
+        """
+        Interprets the significant characters of a code as a number in base N over the character set.
+
+        Args:
+            code: The code to interpret.
+
+        Returns:
+            The numeric value of the code.
+
+        Raises:
+            ValueError: If the code contains characters outside of the character set.
+        """
+        return self.decode_int(self.significant(code))
+
+
+    def from_int(self, value: int, blocksize: int, length: int) -> str:
+        """
+        Encodes a number as a code of the given length in base N over the character set, grouped into blocks.
+        This is the inverse of to_int().
+
+        Args:
+            value: The non-negative number to encode.
+            blocksize: The size of each block within the code.
+            length: The number of characters of the code.
+
+        Returns:
+            The code representing the number.
+
+        Raises:
+            ValueError: If the value is negative or does not fit into length characters.
+        """
+        return self.group(self.encode_int(value, length), blocksize)
+
+
+    def shard_of(self, code: str) -> int:
+        """
+        Decodes the shard identifier from the leading characters of a code generated with shard_id set.
+
+        Raises:
+            ValueError: If no shard identifier is configured or the code is too short.
+        """
+        if self.config['shard_id'] is None:
+            raise ValueError("No shard identifier is configured.")
+        chars: str = self.significant(code)
+        if len(chars) < self.shard_width:
+            raise ValueError("The code is too short to contain a shard identifier.")
+        return self.decode_int(chars[:self.shard_width])
+
+
+    def current_time_bucket(self) -> int:
+        """
+        Returns the number of the current time window, counted since the unix epoch.
+        """
+        return int(self.config['clock']() // self.config['time_bucket'].total_seconds())
+
+
+    def time_segment(self, bucket: int) -> str:
+        """
+        Encodes a time bucket, modulo 2^32, permuted by a Feistel network keyed with the secret into time_width characters.
+        Unlike a fixed offset, observing the segments of some windows does not reveal those of others.
+        """
+        return self.encode_int(self.feistel(bucket % 2 ** 32, 16, key=self.config['time_secret']), self.time_width)
+
+
+    def time_bucket_of(self, code: str) -> int:
+        """
+        Decodes the time bucket from a code generated with time_bucket set.
+        The bucket is recovered modulo 2^32 windows.
+
+        Raises:
+            ValueError: If no time bucket is configured, the code is too short or its time segment is invalid.
+        """
+        if self.config['time_bucket'] is None:
+            raise ValueError("No time bucket is configured.")
+        chars: str = self.significant(code)[self.shard_width:self.shard_width + self.time_width]
+        if len(chars) < self.time_width:
+            raise ValueError("The code is too short to contain a time segment.")
+        value: int = self.decode_int(chars)
+        if value >= 2 ** 32:
+            raise ValueError("The code does not contain a valid time segment.")
+        return self.feistel(value, 16, inverse=True, key=self.config['time_secret'])
+
+
+    def timestamp_of(self, code: str) -> int:
+        """
+        Decodes the unix time of generation from a code generated with timestamp_block set.
+
+        Raises:
+            ValueError: If no timestamp block is configured, the code has no separators to locate it, or is too short.
+        """
+        if self.config['timestamp_block'] is None:
+            raise ValueError("No timestamp block is configured.")
+        if not self.separator:
+            raise ValueError("The timestamp cannot be located without a separator.")
+        start: int = sum(len(block) for block in code.split(self.separator)[:self.config['timestamp_block']])
+        chars: str = self.significant(code)[start:start + self.timestamp_width]
+        if len(chars) < self.timestamp_width:
+            raise ValueError("The code is too short to contain a timestamp.")
+        return self.decode_int(chars)
+
+
+    def is_expired(self, code: str, ttl: datetime.timedelta) -> bool:
+        """
+        Checks whether a code generated with timestamp_block set is older than ttl.
+
+        Args:
+            code: The code to check.
+            ttl: The time to live of the code.
+
+        Returns:
+            True if the code was generated more than ttl ago, False otherwise.
+
+        Raises:
+            ValueError: If the timestamp cannot be decoded from the code.
+        """
+        return self.config['clock']() - self.timestamp_of(code) > ttl.total_seconds()
+
+
+    def check_character(self, chars: str) -> str:
+        """
+        Computes the check character for chars with the configured algorithm, ignoring the character at the checksum position.
+        """
+        position: int = self.config['checksum_position'] % len(chars)
+        if self.config['checksum'] == 'iso7064':
+            return self.iso7064_pure_check_character(chars[:position] + chars[position + 1:], self.character_set)
+        return self.luhn_check_character(chars[:position] + chars[position + 1:], self.character_set)
+
+
+    def validate(self, code: str) -> bool:
+        """
+        Validates the check character of a code generated with checksum enabled.
+        Detects any single wrong character and most swaps of adjacent characters; ISO 7064 detects all of these swaps.
+
+        Args:
+            code: The code to validate.
+
+        Returns:
+            True if the check character matches, False otherwise.
+
+        Raises:
+            ValueError: If the checksum is not enabled.
+        """
+        if not self.config['checksum']:
+            raise ValueError("The checksum is not enabled.")
+        chars: str = self.significant(self.without_checksum_group(code))
+        position: int = self.config['checksum_position']
+        if not -len(chars) <= position < len(chars):
+            return False
+        position %= len(chars)
+        if not set(chars[:position] + chars[position + 1:]) <= set(self.character_set):
+            return False
+        return self.check_character(chars) == chars[position]
+
+
+    def without_checksum_group(self, code: str) -> str:
+        """
+        Returns a code without its trailing check group if checksum_group is enabled, e.g. to verify the other check characters.
+        """
+        return code.rsplit(self.separator, 1)[0] if self.config['checksum_group'] else code
+
+
+    def verify_checksum_group(self, code: str) -> bool:
+        """
+        Verifies the trailing check group of a code generated with checksum_group enabled.
+
+        Args:
+            code: The code to verify.
+
+        Returns:
+            True if the code has a check character for every block and all of them match, False otherwise.
+
+        Raises:
+            ValueError: If the checksum group is not enabled.
+        """
+        if not self.config['checksum_group']:
+            raise ValueError("The checksum group is not enabled.")
+        blocks: List[str] = code.split(self.separator)
+        if len(blocks) < 2 or len(blocks[-1]) != len(blocks) - 1 or not set("".join(blocks)) <= set(self.character_set):
+            return False
+        return "".join(self.luhn_check_character(block, self.character_set) for block in blocks[:-1]) == blocks[-1]
+
+
+    def verify_modulus(self, code: str) -> bool:
+        """
+        Verifies the trailing modulus checksum of a code generated with modulus_checksum enabled.
+        Detects every edit that changes the numeric value of the other characters by an amount not divisible by M.
+
+        Args:
+            code: The code to verify.
+
+        Returns:
+            True if the trailing characters match the value of the others modulo M, False otherwise.
+
+        Raises:
+            ValueError: If the modulus checksum is not enabled.
+        """
+        if self.config['modulus_checksum'] is None:
+            raise ValueError("The modulus checksum is not enabled.")
+        chars: str = self.significant(self.without_checksum_group(code))
+        if len(chars) <= self.modulus_width or not set(chars) <= set(self.character_set):
+            return False
+        value: int = self.decode_int(chars[:-self.modulus_width])
+        return self.decode_int(chars[-self.modulus_width:]) == value % self.config['modulus_checksum']
+
+
+    def error_correction_characters(self, chars: str, level: int) -> str:
+        """
+        Computes the error-correcting check characters appended to chars.
+        With v_i the character set index of the i-th character (1-based) of the complete code
+        and N the size of the character set, the check characters are chosen such that
+        sum(v_i) = 0 (mod N) and, for level 2, sum(i * v_i) = 0 (mod N).
+
+        Args:
+            chars: The characters to protect.
+            level: The number of check characters, 1 or 2.
+
+        Returns:
+            The check characters.
+        """
+        n: int = len(self.character_set)
+        values: List[int] = [self.character_set.index(char) for char in chars]
+        total: int = sum(values)
+        if level == 1:
+            return self.character_set[-total % n]
+        weighted: int = sum(i * value for i, value in enumerate(values, start=1))
+        second: int = (-weighted + (len(chars) + 1) * total) % n
+        first: int = (-total - second) % n
+        return self.character_set[first] + self.character_set[second]
+
+
+    def correct(self, code: str) -> Tuple[str, bool]:
+        """
+        Checks a code generated with error correction and attempts to correct a single wrong character.
+        Level 1 detects any single wrong character. Level 2 also corrects it, which is why it requires
+        the size of the character set to be prime and larger than the length of the code.
+
+        Args:
+            code: The code to check.
+
+        Returns:
+            A tuple of the (possibly corrected) code and whether it is valid now.
+            If the code cannot be corrected, it is returned unchanged together with False.
+        """
+        level: int = self.config['error_correction']
+        if not level:
+            raise ValueError("Error correction is not enabled.")
+        chars: str = self.significant(code)
+        if len(chars) <= level or not set(chars) <= set(self.character_set):
+            return code, False
+
+        n: int = len(self.character_set)
+        values: List[int] = [self.character_set.index(char) for char in chars]
+        error: int = sum(values) % n
+        weighted: int = sum(i * value for i, value in enumerate(values, start=1)) % n
+        if error == 0 and (level == 1 or weighted == 0):
+            return code, True
+        if level == 1 or error == 0:
+            return code, False
+
+        # A single error of size e at position i leaves the syndromes e and i * e
+        positions: List[int] = [i for i in range(1, len(chars) + 1) if i * error % n == weighted]
+        if len(positions) != 1:
+            return code, False
+        position: int = positions[0] - 1
+        corrected: str = self.character_set[(values[position] - error) % n]
+
+        # Map the position back into the code, skipping separators
+        index: int = -1
+        for _ in range(position + 1):
+            index += 1
+            while self.separator and code.startswith(self.separator, index):
+                index += len(self.separator)
+        return code[:index] + corrected + code[index + 1:], True
+
+
+    def pad_block(self, block: str) -> str:
+        """
+        Replaces up to two trailing characters of a block with the padding character, keeping at least one character (AB==).
+        """
+        padding: int = self.rng.randint(0, min(2, len(block) - 1))
+        return block[:len(block) - padding] + self.config['padding_character'] * padding
+
+
+    def chain_checksums(self, blocks: List[str]) -> List[str]:
+        """
+        Replaces the last character of every block with the ISO 7064 MOD N+1,N check character
+        of everything before it, i.e. all preceding blocks plus the block's own leading characters.
+        Every check character therefore covers all previous check characters, forming a chain.
+        Unlike Luhn, the recursive ISO 7064 system depends on the order of the characters,
+        so reordered blocks are detected as well.
+
+        Args:
+            blocks: The blocks to chain.
+
+        Returns:
+            The blocks with their last character replaced by the chained check character.
+        """
+        chained: List[str] = []
+        prefix: str = ""
+        for block in blocks:
+            block = block[:-1] + self.iso7064_check_character(prefix + block[:-1], self.character_set)
+            chained.append(block)
+            prefix += block
+        return chained
+
+
+    def verify_chain(self, code: str) -> bool:
+        """
+        Verifies a string generated with chained_checksums enabled.
+        Changing any single character always breaks the chain; removing or reordering any but the trailing blocks
+        breaks it with high probability. Dropping whole blocks from the end cannot be detected.
+
+        Args:
+            code: The string to verify.
+
+        Returns:
+            True if every block ends with the expected chained check character, False otherwise.
+        """
+        blocks: List[str] = code.split(self.separator) if self.separator else [code]
+        if any(not block or not set(block) <= set(self.character_set) for block in blocks):
+            return False
+        return self.chain_checksums(blocks) == blocks
+
+
+    def is_acceptable(self, output: str) -> bool:
+        """
+        Checks whether a generated string satisfies the configured constraints.
+
+        Args:
+            output: The generated string.
+
+        Returns:
+            True if the string may be returned to the caller, False if it has to be regenerated.
+        """
+        chars: str = self.significant(output)
+
+        # No single character may exceed the configured share of the output
+        if max(Counter(chars).values()) > self.max_char_count(len(chars)): return False
+
+        # Enough consecutive characters have to differ
+        if sum(a != b for a, b in zip(chars, chars[1:])) < self.config['min_transitions']: return False
+
+        # Forbidden bigrams must not appear, also across block boundaries
+        if self.forbidden_bigrams and any(a + b in self.forbidden_bigrams for a, b in zip(chars, chars[1:])): return False
+
+        # The whole string must not read as an ascending or descending run
+        if self.config['non_monotonic']:
+            indices: List[int] = [self.character_set.index(char) for char in chars if char in self.character_set]
+            if indices == sorted(indices) or indices == sorted(indices, reverse=True): return False
+
+        if self.config['min_readability'] and self.readability_score(output) < self.config['min_readability']: return False
+
+        # Blocks changed after rendering, e.g. by checksums, must not be banned either
+        if self.banned_blocks and self.separator and set(output.split(self.separator)) & self.banned_blocks: return False
+        return True
+
+
+    def block_entropy(self, rule: str, blocksize: int) -> float:
+        """
+        Estimates the bits of entropy of a single block rendered by a rule, from the choices the rule makes:
+        its characters, and positions or reversals where applicable. Custom rules are assumed to depend on
+        their two characters only.
+        """
+        bits: float = math.log2(len(self.character_set))
+        estimates: Dict[str, float] = {
+            'repeat': bits,
+            'alternate': bits * min(2, blocksize),
+            'pairs': bits * (2 if blocksize >= 3 else 1),
+            'outlier': bits * min(2, blocksize) + math.log2(math.comb(blocksize, self.outlier_count(blocksize))),
+            'zerofill': bits * min(2, blocksize) + (1 + math.log2(blocksize - 1) if blocksize > 1 else 0),
+            'base64ish': bits * blocksize,
+            'arch': bits + math.log2(max(1, blocksize - 2)),
+            'syllable': bits * min(2, blocksize)
+        }
+        consonants, vowels = self.syllables(self.character_set[0])
+        if consonants:
+            estimates['syllable'] = -(-blocksize // 2) * math.log2(len(consonants)) + blocksize // 2 * math.log2(len(vowels))
+        if rule in self.composites:
+            total: int = sum(self.composites[rule].values())
+            return sum(weight / total * self.block_entropy(member, blocksize) for member, weight in self.composites[rule].items())
+        if rule in self.builtin_rules and self.rules.get(rule) == getattr(self, rule, None):
+            return min(estimates[rule], bits * blocksize)
+        return bits * min(2, blocksize)
+
+
+    def entropy(self, blocksize: int, length: int) -> float:
+        """
+        Estimates the effective bits of entropy of a generated string, i.e. how hard it is to guess.
+        The pattern rules deliberately reduce the entropy below that of uniformly random characters.
+
+        Every block contributes the expected entropy of the eligible rules (see block_entropy()), weighted by the
+        rule selection. The choice of the rule itself is not counted, since different rules may yield the same block.
+        Characters overwritten with deterministic content, such as check characters, shard identifiers or
+        timestamps, reduce the estimate proportionally. Rejected candidates are not taken into account.
+
+        Args:
+            blocksize: The size of each block or pattern within the string.
+            length: The length of the string.
+
+        Returns:
+            The estimated entropy in bits.
+        """
+        bits: float = math.log2(len(self.character_set))
+        composition: Optional[Dict[str, int]] = self.config['exact_composition']
+        interleave: Optional[Tuple[PrettyRandom, PrettyRandom]] = self.config['interleave']
+        if composition is not None:
+            # Characters per class, plus the arrangement of the classes
+            entropy: float = sum(count * math.log2(len(self.classes[name])) for name, count in composition.items())
+            entropy += math.log2(math.factorial(sum(composition.values()))) - sum(math.log2(math.factorial(count)) for count in composition.values())
+        elif interleave is not None:
+            first, second = interleave
+            entropy = first.entropy(min(blocksize, (length + 1) // 2), (length + 1) // 2)
+            if length > 1: entropy += second.entropy(min(blocksize, length // 2), length // 2)
+        elif self.config['checkerboard']:
+            entropy = bits * min(2, length)
+        else:
+            weights: Dict[str, float] = self.rule_weights or {name: 1 / len(self.eligible) for name in self.eligible}
+            entropy = length // blocksize * sum(weight * self.block_entropy(name, blocksize) for name, weight in weights.items())
+            if length % blocksize: entropy += self.block_entropy('alternate', length % blocksize)
+
+        fixed: int = self.shard_width + self.time_width + self.timestamp_width + self.config['error_correction'] + self.modulus_width
+        fixed += (1 if self.config['checksum'] else 0) + (-(-length // blocksize) if self.config['chained_checksums'] else 0)
+        fixed += -(-length // blocksize) if self.config['block_initials'] is not None else 0
+        return max(0.0, entropy * (1 - min(fixed, length) / length))
+
+
+    def readability_score(self, code: str) -> float:
+        """
+        Scores how readable a code is, as the mean of four heuristics in [0, 1] over its significant characters:
+
+        - runs: 1 minus the share of the code taken up by its longest run of a repeated character beyond the first.
+        - transitions: the share of adjacent characters that differ.
+        - sequentials: 1 minus the share of adjacent characters that are neighbours in the character set (e.g. 12, BA).
+        - balance: the entropy of the character classes (numbers, lowercase, uppercase, symbols) relative to the
+          maximum for the classes of the character set; 1 if the character set has a single class.
+
+        Args:
+            code: The code to score.
+
+        Returns:
+            The readability score in [0, 1], higher is more readable.
+        """
+        chars: str = self.significant(code)
+        if len(chars) < 2: return 1.0
+        pairs: List[Tuple[str, str]] = list(zip(chars, chars[1:]))
+
+        longest: int = 1
+        current: int = 1
+        for a, b in pairs:
+            current = current + 1 if a == b else 1
+            longest = max(longest, current)
+        runs: float = 1 - (longest - 1) / (len(chars) - 1)
+
+        transitions: float = sum(a != b for a, b in pairs) / len(pairs)
+
+        neighbours: int = sum(
+            a in self.character_set and b in self.character_set and abs(self.character_set.index(a) - self.character_set.index(b)) == 1
+            for a, b in pairs
+        )
+        sequentials: float = 1 - neighbours / len(pairs)
+
+        enabled: int = sum(1 for members in self.classes.values() if members)
+        balance: float = 1.0
+        if enabled > 1:
+            counts: Counter = Counter(self.character_class(char) for char in chars)
+            entropy: float = -sum(count / len(chars) * math.log(count / len(chars)) for count in counts.values())
+            balance = min(1.0, entropy / math.log(enabled))
+
+        return (runs + transitions + sequentials + balance) / 4
+
+
+    def join(self, blocks: List[str]) -> str:
+        """
+        Joins blocks with the separator. Empty blocks are dropped,
+        so the result never contains adjacent separators or leading/trailing separators.
+        """
+        return self.separator.join(block for block in blocks if block)
+
+
+    def significant(self, code: str) -> str:
+        """
+        Returns the characters of a string without separators.
+        """
+        return code.replace(self.separator, "") if self.separator else code
+
+
+    def max_char_count(self, length: int) -> int:
+        """
+        Returns how often a single character may occur in a string of the given length.
+        """
+        return int(self.config['max_char_frequency'] * length + 1e-9)
+
+
+    def __call__(self, blocksize: Optional[int] = None, length: Optional[int] = None) -> str:
+        """
+        Generates a pretty random string based on the specified blocksize and length.
+        Candidates violating the configured constraints are regenerated, up to max_attempts times.
+
+        Args:
+            blocksize: The size of each block or pattern within the string. Defaults to the blocksize option.
+            length: The desired length of the generated string. Defaults to the length option.
+
+        Returns:
+            A string representing the generated pretty random string.
+
+        Raises:
+            All errors are InvalidLengthError (a ValueError) or GenerationError (a RuntimeError),
+            except for ConfigurationError (a ValueError) if a registered rule misbehaves.
+            ValueError: If the blocksize or length is omitted and not configured.
+            ValueError: If the length is smaller than the blocksize.
+            ValueError: If either the length or blocksize is zero.
+            ValueError: If the configured constraints cannot be met for the given length.
+            ValueError: If the exact composition does not sum up to the length.
+            ValueError: If the length is smaller than the shard identifier and time bucket, or check characters,
+                error correction or padding would overwrite them.
+            ValueError: If there are more blocks than block initials.
+            ValueError: If the length does not exceed the number of error correction characters.
+            ValueError: If the length does not exceed the number of modulus checksum characters.
+            ValueError: If the length does not allow the minimum number of transitions.
+            ValueError: If non_monotonic is set and the length is smaller than three.
+            ValueError: If the timestamp does not fit at the configured block.
+            ValueError: If the checksum position is not within the length.
+            ValueError: If the estimated entropy of the blocksize and length is below min_entropy.
+            ValueError: If a registered rule does not return exactly blocksize characters.
+            RuntimeError: If no candidate satisfied the constraints (or was new to the Bloom filter) within max_attempts attempts.
+            RuntimeError: If the secure entropy source failed.
+        """
+
+        blocksize = self.config['blocksize'] if blocksize is None else blocksize
+        length = self.config['length'] if length is None else length
+        if blocksize is None or length is None:
+            raise InvalidLengthError("Blocksize and length are required unless configured as options.")
+        if length <= 0 or blocksize <= 0:
+            raise InvalidLengthError("Length and Blocksize must be larger than zero.")
+        if length < blocksize:
+            raise InvalidLengthError("Length must be larger or equal to the Blocksize.")
+        if self.max_char_count(length) * len(self.character_set) < length:
+            raise InvalidLengthError("The maximum character frequency cannot be met for this length and character set.")
+        if self.config['exact_composition'] is not None and sum(self.config['exact_composition'].values()) != length:
+            raise InvalidLengthError("The exact composition must sum up to the length.")
+        if length < self.shard_width + self.time_width:
+            raise InvalidLengthError("Length must be larger or equal to the number of characters reserved for the shard identifier and time bucket.")
+        if self.shard_width + self.time_width:
+            # Check characters, error correction and padding are written later and must not overwrite the leading segments
+            overwritten: int = length - self.config['error_correction'] - self.modulus_width
+            if self.config['checksum']: overwritten = min(overwritten, self.config['checksum_position'] % length)
+            if self.config['padding_character'] is not None: overwritten = min(overwritten, max((length - 1) // blocksize * blocksize + 1, length - 2))
+            if overwritten < self.shard_width + self.time_width:
+                raise InvalidLengthError("The check characters and padding must not overlap the shard identifier and time bucket.")
+        if self.config['block_initials'] is not None and len(self.config['block_initials']) < -(-length // blocksize):
+            raise InvalidLengthError("The block initials must provide a letter for every block.")
+        if self.config['timestamp_block'] is not None:
+            reserved: int = self.config['error_correction'] + self.modulus_width + (2 if self.config['padding_character'] is not None else 0)
+            start: int = self.config['timestamp_block'] * blocksize
+            if start < self.shard_width + self.time_width or start + self.timestamp_width > length - reserved:
+                raise InvalidLengthError("The timestamp does not fit into the code at the configured block.")
+        if self.config['checksum'] and not -length <= self.config['checksum_position'] < length:
+            raise InvalidLengthError("The checksum position must be within the length.")
+        if self.config['non_monotonic'] and length < 3:
+            raise InvalidLengthError("Strings shorter than three characters are always monotonic.")
+        if self.config['min_transitions'] > length - 1:
+            raise InvalidLengthError("The minimum number of transitions cannot be met for this length.")
+        if length <= self.config['error_correction']:
+            raise InvalidLengthError("Length must be larger than the number of error correction characters.")
+        if self.config['error_correction'] == 2 and length >= len(self.character_set):
+            raise InvalidLengthError("Correcting errors requires a length smaller than the size of the character set.")
+        if length <= self.modulus_width:
+            raise InvalidLengthError("Length must be larger than the number of modulus checksum characters.")
+        if self.config['min_entropy'] and self.entropy(blocksize, length) < self.config['min_entropy']:
+            raise InvalidLengthError(f"The estimated entropy of {self.entropy(blocksize, length):.1f} bits is below the minimum of {self.config['min_entropy']} bits.")
+
+        with self.lock:
+            for _ in range(self.max_attempts):
+                blocks: List[str] = self.generate_blocks(blocksize, length)
+                output: str = self.group("".join(blocks), self.config['grouping']) if self.config['grouping'] else self.join(blocks)
+                if not self.is_acceptable(output): continue
+                if self.bloom is not None and not self.replaying:
+                    if self.significant(output) in self.bloom: continue
+                    self.bloom.add(self.significant(output))
+                if self.config['checksum_group']:
+                    output = self.join(blocks + ["".join(self.luhn_check_character(block, self.character_set) for block in blocks)])
+                return output
+        raise GenerationError(f"Could not generate a string satisfying the constraints within {self.max_attempts} attempts.")
+
+
+    def __getstate__(self) -> Dict:
+        """
+        Returns the state for copying and pickling, e.g. by clone(); locks and per-thread overrides cannot be copied.
+        """
+        state: Dict = self.__dict__.copy()
+        del state['lock'], state['overrides']
+        return state
+
+
+    def __setstate__(self, state: Dict) -> None:
+        """
+        Restores a copied or unpickled instance with a new lock and without overrides.
+        """
+        self.__dict__.update(state)
+        self.lock = threading.RLock()
+        self.overrides = threading.local()
+
+
+    @contextmanager
+    def overridden(self, **values: object) -> Iterator[None]:
+        """
+        Context manager that overrides ThreadLocal attributes, e.g. rng=random.Random(1), for the current thread only.
+        Other threads keep seeing the shared values. Contexts can be nested.
+        """
+        saved: Dict[str, object] = dict(vars(self.overrides))
+        vars(self.overrides).update(values)
+        try:
+            yield
+        finally:
+            vars(self.overrides).clear()
+            vars(self.overrides).update(saved)
+
+
+    @contextmanager
+    def seeded(self, seed: int) -> Iterator[None]:
+        """
+        Context manager that temporarily replaces the random source with one seeded by seed,
+        making all generation within the context deterministic. Stateful checks such as the Bloom filter
+        are skipped within the context, as they would reject strings issued before. Only the current thread is affected.
+        """
+        with self.overridden(rng=random.Random(seed), replaying=True):
+            yield
+
+
+    @contextmanager
+    def restricted(self, alphabet: List[str]) -> Iterator[None]:
+        """
+        Context manager that temporarily restricts the character set to alphabet,
+        so that all rules draw their characters from it within the context. Only the current thread is affected.
+        """
+        with self.overridden(character_set=alphabet):
+            yield
+
+
+    def from_uuid(self, u: str, blocksize: int, length: int) -> str:
+        """
+        Encodes a UUID as a code in base N over the character set, grouped into blocks. Different UUIDs
+        always map to different codes, so the code can stand in for the UUID, e.g. as a public identifier.
+        The length must provide room for all 128 bits, e.g. 25 characters for the default 36 characters.
+
+        Args:
+            u: The UUID in any format accepted by uuid.UUID, e.g. "123e4567-e89b-12d3-a456-426614174000".
+            blocksize: The size of each block within the code.
+            length: The number of characters of the code.
+
+        Returns:
+            The code representing the UUID; to_int() returns the integer value of the UUID.
+
+        Raises:
+            ValueError: If u is not a valid UUID.
+            InvalidLengthError: If length is too short to encode 128 bits.
+        """
+        value: int = uuid.UUID(u).int
+        required: int = 1
+        while len(self.character_set) ** required < 2 ** 128:
+            required += 1
+        if length < required:
+            raise InvalidLengthError(f"Encoding a UUID in {len(self.character_set)} characters requires a length of at least {required}.")
+        return self.from_int(value, blocksize, length)
+
+
+    def character_class(self, char: str) -> str:
+        """
+        Returns the class of a character: 'numbers', 'lowercase', 'uppercase', 'symbols', or 'other' for any other character.
+        """
+        for name, members in [('numbers', self.numbers), ('lowercase', self.lowercase), ('uppercase', self.uppercase), ('symbols', self.symbols)]:
+            if char in members: return name
+        return 'other'
+
+
+    def generate_with_class_map(self, blocksize: int, length: int) -> Tuple[str, List[str]]:
+        """
+        Generates a pretty random string together with the class of each of its characters, e.g. for highlighting.
+        Separators are not part of the class map.
+
+        Args:
+            blocksize: The size of each block or pattern within the string.
+            length: The desired length of the generated string.
+
+        Returns:
+            A tuple of the string and the list of character classes (see character_class()) of its significant characters.
+        """
+        code: str = self(blocksize, length)
+        return code, [self.character_class(char) for char in self.significant(code)]
+
+
+    def generate_fixtures(self, seed: int, n: int, blocksize: int, length: int) -> List[str]:
+        """
+        Deterministically generates n pretty random strings from a single seed, e.g. for golden-file tests.
+        The same seed, configuration, blocksize and length always yield the same list, across runs and machines.
+
+        Args:
+            seed: The seed of the fixtures.
+            n: The number of strings to generate.
+            blocksize: The size of each block or pattern within the string.
+            length: The desired length of the generated string.
+
+        Returns:
+            A list of n strings.
+        """
+        with self.seeded(seed):
+            return [self(blocksize, length) for _ in range(n)]
+
+
+    def replay(self, seed: int, blocksize: int, length: int) -> Tuple[str, List[Tuple[str, str, str]]]:
+        """
+        Generates the string determined by a seed, skipping stateful checks such as the Bloom filter,
+        so that the same seed always yields the same string.
+
+        Args:
+            seed: The seed of the generation.
+            blocksize: The size of each block or pattern within the string.
+            length: The desired length of the generated string.
+
+        Returns:
+            A tuple of the string and the rule name and characters of every rendered block.
+        """
+        with self.overridden(trace=[]), self.seeded(seed):
+            return self(blocksize, length), self.trace
+
+
+    def generate_traced(self, blocksize: int, length: int) -> Tuple[str, str, List[Tuple[str, str, str]]]:
+        """
+        Generates a pretty random string with its recipe and the rule name and characters of every rendered block.
+        See generate_with_recipe(). Secure generators draw directly from the entropy source instead of from
+        a seed, since a recipe would make their strings reproducible, so their recipe is empty.
+
+        Raises:
+            RuntimeError: If no string new to the Bloom filter was generated within max_attempts attempts.
+        """
+        with self.lock:
+            if self.config['secure']:
+                with self.overridden(trace=[]):
+                    return self(blocksize, length), "", self.trace
+            for _ in range(self.max_attempts):
+                seed: int = self.rng.getrandbits(64)
+                output, trace = self.replay(seed, blocksize, length)
+                if self.bloom is not None:
+                    if self.significant(output) in self.bloom: continue
+                    self.bloom.add(self.significant(output))
+                plan: bytes = json.dumps(trace, ensure_ascii=False, separators=(",", ":")).encode()
+                recipe: str = f"pr2-{seed:016x}-{blocksize}-{length}-{base64.urlsafe_b64encode(plan).decode().rstrip('=')}"
+                return output, recipe, trace
+        raise GenerationError(f"Could not generate a string new to the Bloom filter within {self.max_attempts} attempts.")
+
+
+    def generate_with_recipe(self, blocksize: int, length: int) -> Tuple[str, str]:
+        """
+        Generates a pretty random string together with a recipe (e.g. "pr2-9f2c0d4e1b3a5c7d-4-22-W1sicmVwZWF0Ii...")
+        that reproduces it with generate_from_recipe(), also on another machine.
+        The recipe encodes the seed of the generation, the blocksize, the length and the plan: the rule and
+        the characters chosen for every block. Reproducing requires the same configuration, which the plan verifies,
+        and time-dependent segments are only reproduced within the same time window.
+        Recipes are not available for secure generators, whose strings must not be reproducible.
+
+        Args:
+            blocksize: The size of each block or pattern within the string.
+            length: The desired length of the generated string.
+
+        Returns:
+            A tuple of the string and its recipe.
+
+        Raises:
+            ConfigurationError: If the generator is secure.
+            RuntimeError: If no string new to the Bloom filter was generated within max_attempts attempts.
+        """
+        if self.config['secure']:
+            raise ConfigurationError("Recipes are not available for secure generators.")
+        output, recipe, _ = self.generate_traced(blocksize, length)
+        return output, recipe
+
+
+    def generate_from_recipe(self, recipe: str) -> str:
+        """
+        Regenerates the string described by a recipe returned by generate_with_recipe().
+        Stateful checks such as the Bloom filter are skipped, so a string can be reproduced by the instance that issued it.
+        Recipes without a plan ("pr1-...") are reproduced from their seed without verification.
+
+        Args:
+            recipe: The recipe to reproduce.
+
+        Returns:
+            The reproduced string.
+
+        Raises:
+            ValueError: If the recipe is malformed, or its plan does not match the blocks rendered by this configuration.
+        """
+        parts: List[str] = recipe.split("-", 4)
+        if not (parts[0] == "pr1" and len(parts) == 4 or parts[0] == "pr2" and len(parts) == 5):
+            raise ValueError(f"Invalid recipe {recipe!r}.")
+        try:
+            seed, blocksize, length = int(parts[1], 16), int(parts[2]), int(parts[3])
+            plan: Optional[List[List[str]]] = json.loads(base64.urlsafe_b64decode(parts[4] + "=" * (-len(parts[4]) % 4))) if len(parts) == 5 else None
+        except ValueError:
+            raise ValueError(f"Invalid recipe {recipe!r}.") from None
+        output, trace = self.replay(seed, blocksize, length)
+        if plan is not None and plan != [list(step) for step in trace]:
+            raise ValueError("The recipe does not match the configuration of this generator.")
+        return output
+
+
+    def generate_detailed(self, blocksize: int, length: int) -> Result:
+        """
+        Generates a pretty random string together with the rule and characters of every block and its recipe.
+        The result can be serialized with json.dumps(result.to_dict()). The recipe of secure generators is empty,
+        see generate_traced().
+
+        Args:
+            blocksize: The size of each block or pattern within the string.
+            length: The desired length of the generated string.
+
+        Returns:
+            The string with its blocks and recipe.
+        """
+        output, recipe, trace = self.generate_traced(blocksize, length)
+
+        chars: str = self.significant(output)
+        blocks: List[Block] = []
+        for offset in range(0, length, blocksize):
+            rule, char1, char2 = trace[offset // blocksize] if offset // blocksize < len(trace) else (None, None, None)
+            blocks.append(Block(chars[offset:offset + blocksize], rule, [char1, char2] if rule else [], offset))
+        return Result(output, blocks, recipe)
+
+
+    def generate_within_bytes(self, blocksize: int, max_bytes: int) -> Tuple[str, int]:
+        """
+        Generates a pretty random string that fits into max_bytes bytes when UTF-8 encoded, separators included.
+        Characters are added until the next one would exceed the budget, so with multi-byte characters
+        the string may be shorter than max_bytes characters.
+
+        Args:
+            blocksize: The size of each block or pattern within the string.
+            max_bytes: The maximum number of UTF-8 bytes.
+
+        Returns:
+            A tuple of the string and its actual UTF-8 byte length.
+
+        Raises:
+            ValueError: If not even one character fits into the budget.
+            ValueError: If trailing check characters or padding are enabled, as they would be cut off.
+        """
+        if self.config['chained_checksums'] or self.config['checksum'] or self.config['error_correction'] or self.config['modulus_checksum'] is not None or self.config['padding_character'] is not None:
+            raise ValueError("A byte budget cannot be combined with check characters or padding.")
+
+        code: str = ""
+        size: int = 0
+        # Budgets smaller than the blocksize still fit a partial first block
+        for char in self(blocksize, max(blocksize, max_bytes)):
+            if size + len(char.encode("utf-8")) > max_bytes: break
+            code += char
+            size += len(char.encode("utf-8"))
+
+        # Do not end with a (partial) separator
+        while self.separator and code and code[-1] in self.separator:
+            code = code[:-1]
+        if not code:
+            raise ValueError("Not a single character fits into the byte budget.")
+        return code, len(code.encode("utf-8"))
+
+
+    def feistel(self, value: int, half_bits: int, inverse: bool = False, key: Optional[str] = None) -> int:
+        """
+        Applies a keyed, reversible permutation of [0, 2^(2 * half_bits)) to value,
+        using a four-round Feistel network with HMAC-SHA256 as the round function.
+
+        Args:
+            value: The value to permute.
+            half_bits: The number of bits of each half.
+            inverse: If True, the inverse permutation is applied.
+            key: The key of the permutation. Defaults to the sequence key.
+
+        Returns:
+            The permuted value.
+        """
+        mask: int = (1 << half_bits) - 1
+        left, right = value >> half_bits, value & mask
+
+        def round_function(i: int, half: int) -> int:
+            digest: bytes = hmac.new((self.config['sequence_key'] if key is None else key).encode(), f"{i}:{half}".encode(), hashlib.sha256).digest()
+            return int.from_bytes(digest, "big") & mask
+
+        for i in (reversed(range(4)) if inverse else range(4)):
+            if inverse: left, right = right ^ round_function(i, left), left
+            else: left, right = right, left ^ round_function(i, right)
+        return (left << half_bits) | right
+
+
+    def sequence_bits(self, length: int) -> int:
+        """
+        Returns the number of bits of each Feistel half such that all permuted values fit into length characters.
+        """
+        half_bits: int = 0
+        while 2 ** (2 * (half_bits + 1)) <= len(self.character_set) ** length:
+            half_bits += 1
+        return half_bits
+
+
+    def generate_sequence(self, blocksize: int, length: int) -> str:
+        """
+        Issues the code of the next counter value. Codes are ordered internally, but
+        the counter is scrambled with a keyed permutation, so consecutive codes look unrelated.
+
+        Args:
+            blocksize: The size of each block within the code.
+            length: The number of characters of the code.
+
+        Returns:
+            The code of the next counter value.
+
+        Raises:
+            ValueError: If no sequence key is configured or the length is too short.
+            RuntimeError: If all counter values for this length have been issued.
+        """
+        if self.config['sequence_key'] is None:
+            raise ValueError("No sequence key is configured.")
+        half_bits: int = self.sequence_bits(length)
+        if half_bits == 0:
+            raise ValueError("Length is too short for an obfuscated sequence.")
+        with self.lock:
+            if self.sequence >= 2 ** (2 * half_bits):
+                raise GenerationError("The sequence is exhausted for this length.")
+            code: str = self.from_int(self.feistel(self.sequence, half_bits), blocksize, length)
+            self.sequence += 1
+        return code
+
+
+    def sequence_of(self, code: str) -> int:
+        """
+        Recovers the counter value of a code issued by generate_sequence().
+
+        Raises:
+            ValueError: If no sequence key is configured or the code is not a valid sequence code.
+        """
+        if self.config['sequence_key'] is None:
+            raise ValueError("No sequence key is configured.")
+        half_bits: int = self.sequence_bits(len(self.significant(code)))
+        value: int = self.to_int(code)
+        if half_bits == 0 or value >= 2 ** (2 * half_bits):
+            raise ValueError("The code is not a valid sequence code.")
+        return self.feistel(value, half_bits, inverse=True)
+
+
+    @staticmethod
+    def is_prime(n: int) -> bool:
+        """
+        Returns whether n is a prime number.
+        """
+        return n > 1 and all(n % d for d in range(2, math.isqrt(n) + 1))
+
+
+    @staticmethod
+    def levenshtein(a: str, b: str) -> int:
+        """
+        Returns the Levenshtein (edit) distance between two strings.
+        """
+        previous: List[int] = list(range(len(b) + 1))
+        for i, char_a in enumerate(a, 1):
+            current: List[int] = [i]
+            for j, char_b in enumerate(b, 1):
+                current.append(min(previous[j] + 1, current[j - 1] + 1, previous[j - 1] + (char_a != char_b)))
+            previous = current
+        return previous[-1]
+
+
+    def generate_near(self, base: str, distance: int) -> str:
+        """
+        Generates a code at exactly the given Levenshtein distance from base, e.g. typo variants for testing fuzzy matching.
+        Characters of base are substituted with other characters of the character set, keeping its separators in place.
+
+        Args:
+            base: The code to derive the variant from.
+            distance: The Levenshtein distance between the significant characters of base and the variant.
+
+        Returns:
+            The variant of base.
+
+        Raises:
+            All errors are InvalidLengthError (a ValueError) or GenerationError (a RuntimeError).
+            ValueError: If the distance is negative or exceeds the number of significant characters of base.
+            ValueError: If the distance is positive and the character set offers no substitutes for the characters of base.
+            RuntimeError: If no variant at exactly this distance was found within max_attempts attempts.
+        """
+        chars: str = self.significant(base)
+        if not 0 <= distance <= len(chars):
+            raise InvalidLengthError(f"Distance must be between 0 and {len(chars)}.")
+        substitutable: List[int] = [i for i, char in enumerate(chars) if any(x != char for x in self.character_set)]
+        if len(substitutable) < distance:
+            raise InvalidLengthError(f"The character set offers too few substitutes for a distance of {distance}.")
+
+        for _ in range(self.max_attempts):
+            variant: List[str] = list(chars)
+            for position in self.rng.sample(substitutable, distance):
+                variant[position] = self.rng.choice([x for x in self.character_set if x != chars[position]])
+
+            # Substitutions may be cheaper to undo by shifting, so verify the actual distance
+            if self.levenshtein(chars, "".join(variant)) != distance: continue
+            replacements: Iterator[str] = iter(variant)
+            return "".join(x if self.separator and x in self.separator else next(replacements) for x in base)
+
+        raise GenerationError(f"No variant at distance {distance} found after {self.max_attempts} attempts.")
+
+
+    def fingerprint(self) -> str:
+        """
+        Returns a stable hash of the effective configuration, e.g. as a cache key for generated batches.
+        Instances with the same character set, eligible rules, rule weights and options share a fingerprint,
+        however they were constructed. Functions are identified by their qualified name; anonymous or nested
+        functions such as lambdas are also identified by their object id, so their fingerprints never collide
+        but are only stable within the process.
+
+        Returns:
+            The hexadecimal SHA-256 digest of the canonical configuration.
+        """
+        def canonical(value: object) -> object:
+            if isinstance(value, PrettyRandom): return value.fingerprint()
+            if isinstance(value, datetime.timedelta): return value.total_seconds()
+            if isinstance(value, (set, frozenset)): return sorted(canonical(x) for x in value)
+            if isinstance(value, (list, tuple)): return [canonical(x) for x in value]
+            if isinstance(value, dict): return {str(k): canonical(v) for k, v in sorted(value.items())}
+            if isinstance(value, functools.partial) and value.func == self.custom_rule: return canonical(value.args[0])
+            if callable(value):
+                name: str = f"{getattr(value, '__module__', '')}.{getattr(value, '__qualname__', type(value).__qualname__)}"
+                return f"{name}@{id(value):x}" if '<' in name else name
+            return value
+
+        def rule(name: str) -> object:
+            if name in self.composites: return self.composites[name]
+            if name in self.builtin_rules and self.rules[name] == getattr(self, name, None): return name
+            return canonical(self.rules[name])
+
+        # The character set and the eligible rules already reflect the options selecting them,
+        # and the random source is state, not configuration
+        derived: set[str] = {
+            'rng', 'use_numbers', 'use_lowercase', 'use_uppercase', 'use_symbols', 'characters', 'charset', 'exclude',
+            'exclude_ambiguous', 'reject_homoglyphs', 'font_profile', 'rules', 'rule_selection'
+        }
+        effective: Dict[str, object] = {
+            'character_set': self.character_set,
+            'rules': [[name, rule(name)] for name in self.eligible],
+            'rule_weights': self.rule_weights,
+            'options': {key: value for key, value in self.config.items() if key not in derived}
+        }
+        return hashlib.sha256(json.dumps(canonical(effective), sort_keys=True).encode()).hexdigest()
+
+
+    def generate_from_template(self, mask: str) -> str:
+        """
+        Generates a string of a fixed shape, e.g. "XXXX-XXXX-####". Placeholders are X for any character of the
+        character set, # for a digit and A for a letter; all other characters are copied literally.
+        Every run of the same placeholder is rendered like a block, so the pattern rules still apply.
+
+        Args:
+            mask: The template of the string.
+
+        Returns:
+            The generated string.
+
+        Raises:
+            ValueError: If the mask contains no placeholders, or the character set has no characters for a placeholder.
+        """
+        alphabets: Dict[str, List[str]] = {
+            'X': self.character_set,
+            '#': self.classes['numbers'],
+            'A': [char for char in self.character_set if char in self.classes['lowercase'] or char in self.classes['uppercase']]
+        }
+        if not any(char in alphabets for char in mask):
+            raise ValueError("The mask must contain at least one placeholder (X, # or A).")
+
+        output: str = ""
+        i: int = 0
+        while i < len(mask):
+            placeholder: str = mask[i]
+            run: int = 1
+            while i + run < len(mask) and mask[i + run] == placeholder: run += 1
+            if placeholder not in alphabets:
+                output += mask[i:i + run]
+            elif not alphabets[placeholder]:
+                raise ValueError(f"The character set has no characters for the placeholder {placeholder!r}.")
+            else:
+                with self.restricted(alphabets[placeholder]):
+                    output += self.render_block(self.random_rule, run, output)
+            i += run
+        return output
+
+
+    def generate_range(self, blocksize: int, min_length: int, max_length: int) -> str:
+        """
+        Generates a pretty random string whose length is chosen randomly from [min_length, max_length].
+
+        Args:
+            blocksize: The size of each block or pattern within the string.
+            min_length: The minimum length of the generated string.
+            max_length: The maximum length of the generated string.
+
+        Returns:
+            A string representing the generated pretty random string.
+
+        Raises:
+            ValueError: If min_length is larger than max_length or smaller than the blocksize.
+        """
+        if min_length > max_length:
+            raise ValueError("Minimum length must be smaller or equal to the maximum length.")
+        if min_length < blocksize:
+            raise ValueError("Minimum length must be larger or equal to the Blocksize.")
+        return self(blocksize, self.rng.randint(min_length, max_length))
+
+
+    def generate_n(self, count: int, blocksize: int, length: int, sort: bool = False, key: Optional[Callable[[str], object]] = None) -> List[str]:
+        """
+        Generates count unique pretty random strings.
+
+        Args:
+            count: The number of strings to generate.
+            blocksize: The size of each block or pattern within the string.
+            length: The desired length of the generated string.
+            sort: If True, the strings are returned sorted instead of in generation order.
+            key: An optional sort key, as for sorted(). Defaults to lexicographic order.
+
+        Returns:
+            A list of count unique strings.
+
+        Raises:
+            ValueError: If count is negative.
+            RuntimeError: If count exceeds the number of possible strings, or max_attempts consecutive strings were duplicates,
+                e.g. because the configuration cannot yield enough distinct strings.
+        """
+        codes: List[str] = self.generate_batch(blocksize, length, count)
+        return sorted(codes, key=key) if sort else codes
+
+
+    def generate_batch(self, blocksize: int, length: int, count: int, exists: Optional[Callable[[str], bool]] = None) -> List[str]:
+        """
+        Generates count unique pretty random strings, e.g. for a voucher campaign. Optionally, every candidate is
+        checked against already issued codes, e.g. in a database, and skipped if it exists.
+
+        Args:
+            blocksize: The size of each block or pattern within the string.
+            length: The desired length of the generated string.
+            count: The number of strings to generate.
+            exists: An optional callback returning True for codes that must not be issued again.
+
+        Returns:
+            A list of count unique strings in generation order.
+
+        Raises:
+            ValueError: If count is negative.
+            RuntimeError: If count exceeds the number of possible strings.
+            RuntimeError: If max_attempts consecutive candidates were duplicates or existed,
+                e.g. because the configuration cannot yield enough distinct strings.
+        """
+        if count < 0:
+            raise ValueError("Count must not be negative.")
+        if count > len(self.character_set) ** length:
+            raise GenerationError(f"The character set cannot yield {count} distinct strings of length {length}.")
+
+        codes: Dict[str, None] = {}
+        duplicates: int = 0
+        while len(codes) < count:
+            code: str = self(blocksize, length)
+            duplicates = duplicates + 1 if code in codes or (exists is not None and exists(code)) else 0
+            if duplicates >= self.max_attempts:
+                raise GenerationError(f"Could not generate {count} unique strings.")
+            if duplicates == 0: codes[code] = None
+        return list(codes)
+
+
+    def clone(self, seed: Optional[int] = None) -> 'PrettyRandom':
+        """
+        Returns an independent copy of this instance with its own random source.
+
+        Args:
+            seed: An optional seed for the random source of the copy. Without a seed, secure instances
+                keep drawing from the secure entropy source.
+
+        Returns:
+            The copy.
+        """
+        clone: PrettyRandom = copy.deepcopy(self)
+        clone.rng = SecureRandom() if self.config['secure'] and seed is None else random.Random(seed)
+        return clone
+
+
+    def generate_n_parallel(self, count: int, blocksize: int, length: int, workers: int, seed: Optional[int] = None) -> List[str]:
+        """
+        Generates count unique pretty random strings, splitting the work across worker processes.
+        Every worker uses its own clone of this instance with its own seed; the results are merged and de-duplicated.
+        Instances that cannot be pickled, e.g. with custom rules registered as lambdas, generate the same shares
+        in this process instead. With a Bloom filter, all returned strings are added to the filter of this instance.
+
+        Args:
+            count: The number of strings to generate.
+            blocksize: The size of each block or pattern within the string.
+            length: The desired length of the generated string.
+            workers: The number of worker processes.
+            seed: An optional seed making the result reproducible.
+
+        Returns:
+            A list of count unique strings.
+
+        Raises:
+            ValueError: If count is negative or workers is not larger than zero.
+            RuntimeError: If the configuration cannot yield enough distinct strings.
+        """
+        if count < 0:
+            raise ValueError("Count must not be negative.")
+        if workers <= 0:
+            raise ValueError("The number of workers must be larger than zero.")
+
+        seeds: random.Random = random.Random(seed) if seed is not None else self.rng
+
+        # Secure workers draw from the entropy source themselves instead of from a drawn seed
+        def worker_seed() -> Optional[int]:
+            return None if self.config['secure'] and seed is None else seeds.getrandbits(64)
+
+        clones: List[PrettyRandom] = [self.clone(worker_seed()) for _ in range(workers)]
+        shares: List[int] = [count // workers + (1 if i < count % workers else 0) for i in range(workers)]
+        try:
+            pickle.dumps(clones[0])
+            picklable: bool = True
+        except (pickle.PicklingError, AttributeError, TypeError):
+            picklable = False
+        batches: List[List[str]]
+        if picklable:
+            with ProcessPoolExecutor(max_workers=workers) as executor:
+                batches = list(executor.map(generate_unique, clones, shares, repeat(blocksize), repeat(length)))
+        else:
+            batches = list(map(generate_unique, clones, shares, repeat(blocksize), repeat(length)))
+
+        # Workers do not see each other's strings, so replace duplicates across workers
+        codes: Dict[str, None] = dict.fromkeys(code for batch in batches for code in batch)
+        generator: PrettyRandom = self.clone(worker_seed())
+        duplicates: int = 0
+        while len(codes) < count:
+            code: str = generator(blocksize, length)
+            duplicates = duplicates + 1 if code in codes else 0
+            if duplicates >= self.max_attempts:
+                raise GenerationError(f"Could not generate {count} unique strings.")
+            codes[code] = None
+
+        # The clones filled copies of the Bloom filter, so record the issued strings in the original one
+        if self.bloom is not None:
+            with self.lock:
+                for code in codes: self.bloom.add(self.significant(code))
+        return list(codes)
+
+
+    def generate_batch_with_prefixes(self, prefixes: List[str], count_each: int, blocksize: int, length: int) -> List[str]:
+        """
+        Generates count_each pretty random strings for every prefix, distributing the prefixes round-robin.
+        Each prefix is placed as its own leading block (e.g. "EU 4040 AAAA").
+
+        Args:
+            prefixes: The prefixes to distribute across the batch.
+            count_each: The number of strings to generate per prefix.
+            blocksize: The size of each block or pattern within the string.
+            length: The desired length of the generated string, excluding the prefix.
+
+        Returns:
+            A list of len(prefixes) * count_each strings, cycling through the prefixes.
+
+        Raises:
+            ValueError: If no prefixes are given, a prefix is empty, duplicated or contains the separator.
+            ValueError: If count_each is negative.
+        """
+        if not prefixes:
+            raise ValueError("At least one prefix has to be provided.")
+        if len(set(prefixes)) != len(prefixes):
+            raise ValueError("Prefixes must be unique.")
+        for prefix in prefixes:
+            if not prefix or (self.separator and self.separator in prefix):
+                raise ValueError(f"Invalid prefix {prefix!r}: prefixes must be non-empty and must not contain the separator.")
+        if count_each < 0:
+            raise ValueError("Count must not be negative.")
+
+        return [self.join([prefix, self(blocksize, length)]) for _ in range(count_each) for prefix in prefixes]
+
+
+
+    def generate_stream(self, stream: TextIO, count: int, blocksize: int, length: int, flush_every: int = 1000) -> None:
+        """
+        Writes count newline-separated pretty random strings to a stream without building them up in memory.
+        The stream is flushed every flush_every strings and once more at the end.
+        Write errors are raised immediately.
+
+        Args:
+            stream: A writable text stream, e.g. an open file.
+            count: The number of strings to write.
+            blocksize: The size of each block or pattern within the string.
+            length: The desired length of the generated string.
+            flush_every: The number of strings written between two flushes.
+
+        Raises:
+            ValueError: If count is negative or flush_every is not larger than zero.
+        """
+        if count < 0:
+            raise ValueError("Count must not be negative.")
+        if flush_every <= 0:
+            raise ValueError("Flush interval must be larger than zero.")
+
+        for i in range(1, count + 1):
+            stream.write(self(blocksize, length) + "\n")
+            if i % flush_every == 0: stream.flush()
+        stream.flush()
+
+
+    def reader(self, blocksize: int) -> Reader:
+        """
+        Returns an endless text stream of pretty random blocks, e.g. for piping into another process.
+        Only the rules apply; constraints and post-processing such as checksums do not.
+
+        Args:
+            blocksize: The size of each block.
+
+        Returns:
+            A readable text stream; read() requires a size.
+        """
+        return Reader(self, blocksize)
+
+
+    def generate_to(self, stream: TextIO, blocksize: int, length: int) -> int:
+        """
+        Writes a single pretty random string of the given length to a stream block by block, without building it up in memory.
+        Only the rules apply; constraints and post-processing such as checksums do not.
+
+        Args:
+            stream: A writable text stream, e.g. an open file.
+            blocksize: The size of each block or pattern within the string.
+            length: The number of characters, excluding separators.
+
+        Returns:
+            The number of characters written, including separators.
+
+        Raises:
+            ValueError: If either the length or blocksize is zero, or the length is smaller than the blocksize.
+        """
+        if length <= 0 or blocksize <= 0:
+            raise ValueError("Length and Blocksize must be larger than zero.")
+        if length < blocksize:
+            raise ValueError("Length must be larger or equal to the Blocksize.")
+
+        written: int = 0
+        history: str = ""
+        for offset in range(0, length, blocksize):
+            # The remainder is filled with the alternate pattern, as in generate_blocks()
+            size: int = min(blocksize, length - offset)
+            with self.lock:
+                block: str = self.render_block(self.random_rule if size == blocksize else lambda: self.alternate, size, history)
+            history = (history + block)[-self.config['markov_order']:]
+            written += stream.write((self.separator if offset else "") + block)
+        return written
+
+
+
+    @staticmethod
+    def luhn_check_character(chars: str, alphabet: List[str]) -> str:
+        """
+        Computes the Luhn mod N check character of a string over the given alphabet.
+
+        Args:
+            chars: The characters to compute the check character for.
+            alphabet: The ordered alphabet; its size is N.
+
+        Returns:
+            The check character, taken from the alphabet.
+
+        Raises:
+            ValueError: If a character is not part of the alphabet.
+        """
+        n: int = len(alphabet)
+        total: int = 0
+        factor: int = 2
+        for char in reversed(chars):
+            if char not in alphabet:
+                raise ValueError(f"Character {char!r} is not part of the alphabet.")
+            addend: int = factor * alphabet.index(char)
+            total += addend // n + addend % n
+            factor = 1 if factor == 2 else 2
+        return alphabet[(n - total % n) % n]
+
+
+    @staticmethod
+    def iso7064_check_character(chars: str, alphabet: List[str]) -> str:
+        """
+        Computes the ISO 7064 hybrid MOD N+1,N check character of a string over the given alphabet.
+
+        Args:
+            chars: The characters to compute the check character for.
+            alphabet: The ordered alphabet; its size is N.
+
+        Returns:
+            The check character, taken from the alphabet.
+
+        Raises:
+            ValueError: If a character is not part of the alphabet.
+        """
+        n: int = len(alphabet)
+        product: int = n
+        for char in chars:
+            if char not in alphabet:
+                raise ValueError(f"Character {char!r} is not part of the alphabet.")
+            total: int = (product + alphabet.index(char)) % n or n
+            product = (2 * total) % (n + 1)
+        return alphabet[(n + 1 - product) % n]
+
+
+    @staticmethod
+    def iso7064_pure_check_character(chars: str, alphabet: List[str], supplementary: str = "*") -> str:
+        """
+        Computes the ISO 7064 pure MOD N+1-2 check character of a string over the given alphabet,
+        e.g. MOD 37-2 for the 36 numbers and uppercase letters. The check value N is represented by
+        the supplementary character.
+
+        Args:
+            chars: The characters to compute the check character for.
+            alphabet: The ordered alphabet; its size is N, and N+1 has to be prime.
+            supplementary: The check character for the check value N.
+
+        Returns:
+            The check character, taken from the alphabet or the supplementary character.
+
+        Raises:
+            ValueError: If a character is not part of the alphabet.
+        """
+        modulus: int = len(alphabet) + 1
+        remainder: int = 0
+        for char in chars:
+            if char not in alphabet:
+                raise ValueError(f"Character {char!r} is not part of the alphabet.")
+            remainder = (remainder + alphabet.index(char)) * 2 % modulus
+        check: int = (modulus + 1 - remainder) % modulus
+        return supplementary if check == len(alphabet) else alphabet[check]
+
+
+    @classmethod
+    def from_profile(cls, name: str, **kwargs) -> 'PrettyRandom':
+        """
+        Returns a generator for a common code format, configured with its default blocksize and length:
+        PrettyRandom.from_profile('gift_card')() generates 1234 5678 9012 3456. See PROFILES.
+
+        Args:
+            name: The name of the profile, e.g. 'license_key', 'gift_card', 'pin' or 'hex_token'.
+            **kwargs: Options overriding those of the profile, e.g. length=8 or secure=True.
+
+        Raises:
+            ValueError: If the profile is unknown, or as described in __init__().
+        """
+        if name not in PROFILES:
+            raise ConfigurationError(f"Unknown profile {name!r}.")
+        return cls(**{**PROFILES[name], **kwargs})
+
+
+    @classmethod
+    def pronounceable(cls, **kwargs) -> 'PrettyRandom':
+        """
+        Returns a generator of memorable codes built from consonant-vowel syllables (BAKO MIRU), e.g. to read aloud.
+        It uses uppercase letters and the syllable rule only; any option can be overridden by keyword arguments.
+        """
+        return cls(**{'use_numbers': False, 'rule_selection': 'syllable', **kwargs})
+
+
+    @classmethod
+    def license_key(cls, checksum: bool = False) -> str:
+        """
+        Generates a software license key of 5 hyphen-separated groups of 5 uppercase letters and numbers (A1B2C-3D4E5-...),
+        using the license_key profile.
+
+        Args:
+            checksum: If True, a sixth group is appended whose i-th character is the Luhn mod 36 check character of the i-th group.
+
+        Returns:
+            A string representing the generated license key.
+        """
+        return cls.from_profile('license_key', checksum_group=checksum)()
+
+
+    @classmethod
+    def verify_license_key(cls, key: str) -> bool:
+        """
+        Verifies the checksum group of a license key generated with license_key(checksum=True) or the license_key profile.
+
+        Args:
+            key: The license key to verify.
+
+        Returns:
+            True if the key has the expected shape and its checksum group matches, False otherwise.
+        """
+        generator = cls.from_profile('license_key')
+        groups: List[str] = key.split(generator.separator)
+        if len(groups) != 6 or any(len(group) != 5 for group in groups):
+            return False
+        return generator.verify_checksum_group(key)
+
+
+
+    def group(self, chars: str, blocksize: int) -> str:
+        """
+        Splits a string into separated blocks of blocksize characters; the last block may be shorter.
+        """
+        if blocksize <= 0:
+            raise ValueError("Blocksize must be larger than zero.")
+        return self.join([chars[i:i + blocksize] for i in range(0, len(chars), blocksize)])
+
+
+    def reformat_using(self, code: str, other: 'PrettyRandom', blocksize: Optional[int] = None) -> str:
+        """
+        Re-renders a code generated by this instance in the layout of another instance:
+        the separators of this instance are stripped and the characters are regrouped
+        with the separator of the other instance.
+
+        Args:
+            code: The code generated by this instance.
+            other: The instance whose layout should be applied.
+            blocksize: The size of each block in the new layout. Defaults to the grouping of the other instance if set,
+                otherwise to the size of the code's first block.
+
+        Returns:
+            The code in the layout of the other instance.
+
+        Raises:
+            ValueError: If both instances do not share the same character set.
+            ValueError: If the code contains characters outside of the character set.
+        """
+        if set(self.character_set) != set(other.character_set):
+            raise ValueError("Both instances must use the same character set.")
+        chars: str = self.significant(code)
+        if not set(chars) <= set(self.character_set):
+            raise ValueError("The code contains characters outside of the character set.")
+        if blocksize is None and other.config['grouping']:
+            blocksize = other.config['grouping']
+        if blocksize is None:
+            blocksize = len(code.split(self.separator)[0]) if self.separator else len(chars)
+        return other.group(chars, blocksize)
+
+
+    def generate_from_allowlist(self, blocksize: int) -> str:
+        """
+        Randomly draws a not yet used code from the allowlist and formats it into blocks.
+        Never invents new codes.
+
+        Args:
+            blocksize: The size of each block within the string.
+
+        Returns:
+            The drawn code, grouped into blocks of blocksize characters.
+
+        Raises:
+            ValueError: If no allowlist is configured.
+            RuntimeError: If all codes of the allowlist have been used.
+        """
+        if self.allowlist is None:
+            raise ValueError("No allowlist is configured.")
+        with self.lock:
+            if not self.allowlist:
+                raise GenerationError("The allowlist is exhausted.")
+            code: str = self.allowlist.pop(self.rng.randrange(len(self.allowlist)))
+        return self.group(code, blocksize)
+
+
+
+    @staticmethod
+    def display_width(code: str) -> int:
+        """
+        Computes the number of terminal columns a string occupies.
+        East Asian wide and full-width characters occupy two columns, combining characters none.
+
+        Args:
+            code: The string to measure.
+
+        Returns:
+            The display width of the string.
+        """
+        width: int = 0
+        for char in code:
+            if unicodedata.combining(char): continue
+            width += 2 if unicodedata.east_asian_width(char) in ('W', 'F') else 1
+        return width
+
+
+    @classmethod
+    def pad_to_width(cls, code: str, width: int, fillchar: str = " ") -> str:
+        """
+        Pads a string on the right so that it occupies width terminal columns, e.g. for aligned columns.
+
+        Args:
+            code: The string to pad.
+            width: The desired display width.
+            fillchar: A single-column character used for padding.
+
+        Returns:
+            The padded string, or the string itself if it is already at least width columns wide.
+        """
+        return code + fillchar * max(0, width - cls.display_width(code))
+
+
+
+    def normalize(self, code: str) -> str:
+        """
+        Converts a code as typed by a user into its canonical form for storage.
+        Depending on the configuration, separators are stripped and the code is uppercased.
+        Separator characters that are part of the character set are kept.
+
+        Args:
+            code: The code to normalize.
+
+        Returns:
+            The normalized code.
+        """
+        if self.config['normalize_separators']:
+            code = "".join(char for char in self.significant(code) if char in self.character_set or not (char.isspace() or char in "-_."))
+        if self.config['normalize_case']:
+            code = code.upper()
+        return code
+
+
+    def generate_with_normalized(self, blocksize: int, length: int) -> Tuple[str, str]:
+        """
+        Generates a pretty random string together with its normalized form.
+
+        Args:
+            blocksize: The size of each block or pattern within the string.
+            length: The desired length of the generated string.
+
+        Returns:
+            A tuple of the string for display and its normalized form for storage.
+        """
+        display: str = self(blocksize, length)
+        return display, self.normalize(display)
+
+
+def generate_unique(generator: PrettyRandom, count: int, blocksize: int, length: int) -> List[str]:
+    """
+    Generates count unique strings with the given generator; the task of a worker process in generate_n_parallel().
+    """
+    return generator.generate_n(count, blocksize, length)
@@ -0,0 +1,42 @@
+from dataclasses import asdict, dataclass, field
+from typing import Dict, List, Optional
+
+
+@dataclass
+class Block:
+    """
+    A block of a generated string and how it was produced.
+
+    Attributes:
+        text: The characters of the block in the final string, after checksums and other post-processing.
+        rule: The name of the rule that rendered the block, or None if it was not rendered by a rule,
+            e.g. with an exact composition.
+        chars: The two characters drawn for the rule, before the optional swap.
+        offset: The index of the first character of the block among the significant characters.
+    """
+    text: str
+    rule: Optional[str]
+    chars: List[str]
+    offset: int
+
+
+@dataclass
+class Result:
+    """
+    A generated string with per-block metadata, e.g. for debugging or analytics.
+
+    Attributes:
+        output: The generated string.
+        blocks: The blocks of the string, in order.
+        recipe: The recipe reproducing the string, see PrettyRandom.generate_from_recipe(). Empty for secure generators.
+    """
+    output: str
+    blocks: List[Block] = field(default_factory=list)
+    recipe: str = ""
+
+
+    def to_dict(self) -> Dict:
+        """
+        Returns the result as a dictionary of plain values, ready for json.dumps().
+        """
+        return asdict(self)
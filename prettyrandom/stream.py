@@ -0,0 +1,56 @@
+import io
+from typing import List
+
+
+class Reader(io.TextIOBase):
+    """
+    An endless, read-only text stream of pretty random blocks joined by the generator's separator,
+    e.g. to pipe large amounts of characters into another process. See PrettyRandom.reader().
+    """
+
+    def __init__(self, generator: 'PrettyRandom', blocksize: int) -> None:
+        """
+        Initializes the stream.
+
+        Args:
+            generator: The generator rendering the blocks.
+            blocksize: The size of each block.
+
+        Raises:
+            ValueError: If the blocksize is not larger than zero.
+        """
+        if blocksize <= 0:
+            raise ValueError("Blocksize must be larger than zero.")
+        self.generator = generator
+        self.blocksize: int = blocksize
+        self.buffer: str = ""
+        self.history: str = ""
+        self.started: bool = False
+
+
+    def readable(self) -> bool:
+        return True
+
+
+    def read(self, size: int = -1) -> str:
+        """
+        Reads exactly size characters from the stream.
+
+        Raises:
+            ValueError: If size is negative or omitted, since the stream never ends.
+        """
+        if size is None or size < 0:
+            raise ValueError("The stream is endless; a non-negative size is required.")
+        chunks: List[str] = [self.buffer]
+        available: int = len(self.buffer)
+        while available < size:
+            with self.generator.lock:
+                block: str = self.generator.render_block(self.generator.random_rule, self.blocksize, self.history)
+            self.history = (self.history + block)[-self.generator.config['markov_order']:]
+            chunk: str = (self.generator.separator if self.started else "") + block
+            self.started = True
+            chunks.append(chunk)
+            available += len(chunk)
+        data: str = "".join(chunks)
+        self.buffer = data[size:]
+        return data[:size]
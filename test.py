@@ -1,6 +1,22 @@
+import base64
+import contextlib
+import datetime
+import io
+import json
+import math
+import os
+import random
+import subprocess
+import sys
+import tempfile
+import threading
 import unittest
-from typing import List
+import uuid
+from concurrent.futures import ThreadPoolExecutor
+from unittest import mock
+from typing import Dict, List
 import prettyrandom
+from prettyrandom import __main__ as cli
 
 class Test(unittest.TestCase):
     def setUp(self) -> None:
@@ -35,3 +51,1604 @@ class Test(unittest.TestCase):
                 blocks: List[str] = x.split(" ")
                 if len(blocks) > 1 and len(blocks[-1]) != len(blocks[-2]): blocks = blocks[:-1]
                 for b in blocks: self.assertEqual(len(b), blocksize)
+
+
+    def test_homoglyphs(self) -> None:
+        """
+        Test case to ensure that a mixed alphabet with homoglyphs is cleaned or rejected.
+
+        Builds an alphabet containing Latin characters and their Cyrillic/Greek lookalikes.
+        Asserts that only the Latin characters are kept, or that an error is raised in strict mode.
+        """
+        generator = prettyrandom.PrettyRandom(characters="ABC\u0410\u0412\u0394")
+        self.assertEqual(generator.character_set, ['A', 'B', 'C', '\u0394'])
+        with self.assertRaises(ValueError):
+            prettyrandom.PrettyRandom(characters="ABC\u0410", reject_homoglyphs=True)
+
+
+    def test_batch_with_prefixes(self) -> None:
+        """
+        Test case to ensure that a batch generated with prefixes contains the expected number of strings per prefix.
+
+        Generates a batch for three region prefixes and counts the strings starting with each prefix.
+        Asserts that the prefixes are distributed round-robin and evenly.
+        """
+        prefixes: List[str] = ["EU", "US", "APAC"]
+        batch: List[str] = self.prettyrandom_generator.generate_batch_with_prefixes(prefixes, 5, 4, 12)
+        self.assertEqual(len(batch), 15)
+        self.assertEqual([x.split(" ")[0] for x in batch[:3]], prefixes)
+        for prefix in prefixes:
+            self.assertEqual(sum(1 for x in batch if x.split(" ")[0] == prefix), 5)
+        with self.assertRaises(ValueError):
+            self.prettyrandom_generator.generate_batch_with_prefixes(["EU", ""], 5, 4, 12)
+
+
+    def test_stream(self) -> None:
+        """
+        Test case to ensure that streaming writes the requested number of newline-separated strings.
+
+        Streams strings into an in-memory buffer with a small flush interval.
+        Asserts that the number of lines matches the requested count and each line has the expected length.
+        """
+        buffer = io.StringIO()
+        self.prettyrandom_generator.generate_stream(buffer, 250, 4, 16, flush_every=100)
+        lines: List[str] = buffer.getvalue().splitlines()
+        self.assertEqual(len(lines), 250)
+        for line in lines: self.assertEqual(len(line.replace(" ", "")), 16)
+
+
+    def test_license_key(self) -> None:
+        """
+        Test case to ensure that license keys have the expected shape and a valid checksum group.
+
+        Generates license keys with and without checksum and matches them against the expected layout.
+        Asserts that the checksum verifies and that changing a single character invalidates it.
+        """
+        self.assertRegex(prettyrandom.PrettyRandom.license_key(), r"^[0-9A-Z]{5}(-[0-9A-Z]{5}){4}$")
+        for _ in range(50):
+            key: str = prettyrandom.PrettyRandom.license_key(checksum=True)
+            self.assertRegex(key, r"^[0-9A-Z]{5}(-[0-9A-Z]{5}){5}$")
+            self.assertTrue(prettyrandom.PrettyRandom.verify_license_key(key))
+            tampered: str = ("1" if key[0] != "1" else "2") + key[1:]
+            self.assertFalse(prettyrandom.PrettyRandom.verify_license_key(tampered))
+
+
+    def test_max_char_frequency(self) -> None:
+        """
+        Test case to ensure that no character exceeds the configured frequency cap.
+
+        Generates strings with a frequency cap of 50% and counts the occurrences of each character.
+        Asserts that no character occupies more than half of the string, and that impossible caps are rejected.
+        """
+        generator = prettyrandom.PrettyRandom(max_char_frequency=0.5)
+        for length in range(8, 40):
+            x: str = generator(4, length).replace(" ", "")
+            for char in set(x): self.assertLessEqual(x.count(char), length * 0.5)
+        with self.assertRaises(ValueError):
+            generator(1, 1)
+        with self.assertRaises(ValueError):
+            prettyrandom.PrettyRandom(max_char_frequency=0)
+
+
+    def test_chained_checksums(self) -> None:
+        """
+        Test case to ensure that chained checksums verify and detect tampering.
+
+        Generates strings with chained checksums and verifies them.
+        Asserts that altering a character of the first block or swapping the first two blocks invalidates the chain.
+        """
+        generator = prettyrandom.PrettyRandom(chained_checksums=True)
+        for _ in range(50):
+            x: str = generator(4, 22)
+            self.assertTrue(generator.verify_chain(x))
+            self.assertFalse(generator.verify_chain(("1" if x[0] != "1" else "2") + x[1:]))
+            blocks: List[str] = x.split(" ")
+            if blocks[0] != blocks[1]:
+                self.assertFalse(generator.verify_chain(" ".join([blocks[1], blocks[0]] + blocks[2:])))
+
+
+    def test_allowlist(self) -> None:
+        """
+        Test case to ensure that codes are drawn from the allowlist without repetition.
+
+        Draws every code from an allowlist and compares the drawn codes with the pool.
+        Asserts that all entries are drawn exactly once, formatted into blocks, and that drawing from the exhausted pool fails.
+        """
+        pool: List[str] = ["AAAA1111", "ABAB2222", "ZZZZ9999", "1212XYXY"]
+        generator = prettyrandom.PrettyRandom(allowlist=pool)
+        drawn: List[str] = [generator.generate_from_allowlist(4) for _ in pool]
+        self.assertEqual(sorted(x.replace(" ", "") for x in drawn), sorted(pool))
+        for x in drawn: self.assertEqual([len(b) for b in x.split(" ")], [4, 4])
+        with self.assertRaises(RuntimeError):
+            generator.generate_from_allowlist(4)
+
+
+    def test_display_width(self) -> None:
+        """
+        Test case to ensure that the display width accounts for full-width characters.
+
+        Measures strings mixing full-width and ASCII characters and pads them to a common width.
+        Asserts that full-width characters count as two columns and that padded strings align.
+        """
+        self.assertEqual(prettyrandom.PrettyRandom.display_width("AB12"), 4)
+        self.assertEqual(prettyrandom.PrettyRandom.display_width("Ａ1漢字"), 7)
+        for x in ["AB12", "Ａ1漢字", "ｶ"]:
+            self.assertEqual(prettyrandom.PrettyRandom.display_width(prettyrandom.PrettyRandom.pad_to_width(x, 10)), 10)
+
+
+    def test_normalized(self) -> None:
+        """
+        Test case to ensure that normalizing the display form yields the returned normalized form.
+
+        Generates display and normalized forms and normalizes user-typed variants of the display form.
+        Asserts that all variants normalize to the returned value, and that normalization can be configured.
+        """
+        generator = prettyrandom.PrettyRandom(use_lowercase=True)
+        for _ in range(50):
+            display, normalized = generator.generate_with_normalized(4, 22)
+            self.assertEqual(generator.normalize(display), normalized)
+            self.assertEqual(generator.normalize(display.replace(" ", "-").lower()), normalized)
+            self.assertNotIn(" ", normalized)
+        generator = prettyrandom.PrettyRandom(use_lowercase=True, normalize_case=False, normalize_separators=False)
+        self.assertEqual(generator.normalize("ab-CD 12"), "ab-CD 12")
+
+
+    def test_separator(self) -> None:
+        """
+        Test case to ensure that separators are never doubled and blocks are never empty.
+
+        Generates strings with multi-character and empty separators for pathological lengths and block sizes.
+        Asserts that no separators are adjacent, none lead or trail, and the significant length is preserved.
+        """
+        generator = prettyrandom.PrettyRandom(separator="--")
+        for length in range(1, 30):
+            for blocksize in [1, length - 1, length]:
+                if blocksize <= 0: continue
+                x: str = generator(blocksize, length)
+                self.assertNotIn("----", x)
+                self.assertFalse(x.startswith("--") or x.endswith("--"))
+                self.assertNotIn("", x.split("--"))
+                self.assertEqual(len(generator.significant(x)), length)
+        self.assertEqual(generator.join(["AB", "", "CD", ""]), "AB--CD")
+        self.assertEqual(len(prettyrandom.PrettyRandom(separator="")(4, 22)), 22)
+        with self.assertRaises(ValueError):
+            prettyrandom.PrettyRandom(separator="A")
+
+
+    def test_base64ish(self) -> None:
+        """
+        Test case to ensure that the base64ish rule groups quads and only pads the final block.
+
+        Generates strings using only the base64ish rule with and without a padding character.
+        Asserts that all blocks are quads and that padding only occurs at the end of the final block.
+        """
+        generator = prettyrandom.PrettyRandom(padding_character="=")
+        generator.use_rules('base64ish')
+        for _ in range(100):
+            blocks: List[str] = generator(4, 16).split(" ")
+            for b in blocks: self.assertEqual(len(b), 4)
+            for b in blocks[:-1]: self.assertNotIn("=", b)
+            self.assertRegex(blocks[-1], r"^[0-9A-Z]{2,4}={0,2}$")
+        generator = prettyrandom.PrettyRandom()
+        generator.use_rules('base64ish')
+        self.assertNotIn("=", generator(4, 16))
+
+
+    def test_range(self) -> None:
+        """
+        Test case to ensure that strings generated for a length range fall within the range.
+
+        Generates many strings for a length range and collects their lengths.
+        Asserts that every length lies within the range and that invalid ranges are rejected.
+        """
+        lengths: set[int] = {len(self.prettyrandom_generator.generate_range(4, 8, 12).replace(" ", "")) for _ in range(200)}
+        self.assertTrue(lengths <= set(range(8, 13)))
+        self.assertGreater(len(lengths), 1)
+        with self.assertRaises(ValueError):
+            self.prettyrandom_generator.generate_range(4, 12, 8)
+        with self.assertRaises(ValueError):
+            self.prettyrandom_generator.generate_range(4, 3, 8)
+
+
+    def test_from_uuid(self) -> None:
+        """
+        Test case to ensure that UUIDs map to distinct strings, the same UUID always to the same string.
+
+        Derives strings from UUIDs repeatedly, with separate instances and from UUIDs differing in a single bit.
+        Asserts that equal UUIDs in different notations map to equal strings, distinct UUIDs to distinct strings,
+        and that invalid UUIDs and lengths too short for 128 bits are rejected.
+        """
+        u: str = "123e4567-e89b-12d3-a456-426614174000"
+        x: str = self.prettyrandom_generator.from_uuid(u, 4, 25)
+        self.assertEqual(prettyrandom.PrettyRandom().from_uuid(u, 4, 25), x)
+        self.assertEqual(self.prettyrandom_generator.from_uuid(u.replace("-", "").upper(), 4, 25), x)
+        self.assertEqual(len(x.replace(" ", "")), 25)
+        self.assertEqual(self.prettyrandom_generator.to_int(x), uuid.UUID(u).int)
+        self.assertNotEqual(self.prettyrandom_generator.from_uuid("00000000-0000-0000-0000-000000000001", 4, 25), x)
+
+        uuids: List[str] = [str(uuid.UUID(int=uuid.UUID(u).int ^ (1 << bit))) for bit in range(128)]
+        self.assertEqual(len(set(self.prettyrandom_generator.from_uuid(v, 4, 25) for v in uuids)), 128)
+        self.assertEqual(len(prettyrandom.PrettyRandom(characters="01").from_uuid(u, 8, 128).replace(" ", "")), 128)
+        with self.assertRaises(prettyrandom.InvalidLengthError):
+            self.prettyrandom_generator.from_uuid(u, 4, 24)
+        with self.assertRaises(ValueError):
+            self.prettyrandom_generator.from_uuid("not-a-uuid", 4, 25)
+
+
+    def test_swap_probability(self) -> None:
+        """
+        Test case to ensure that the swap probability controls which character is primary.
+
+        Applies the repeat rule with swap probabilities of 0 and 1, and generates strings under a fixed seed.
+        Asserts that no swap occurs at probability 0, always occurs at probability 1, and that seeded output is deterministic.
+        """
+        generator = prettyrandom.PrettyRandom(swap_probability=0)
+        for _ in range(50): self.assertEqual(generator.apply_rule(generator.repeat, "A", "B", 4), "AAAA")
+        generator = prettyrandom.PrettyRandom(swap_probability=1)
+        for _ in range(50): self.assertEqual(generator.apply_rule(generator.repeat, "A", "B", 4), "BBBB")
+        x: List[str] = [prettyrandom.PrettyRandom(swap_probability=0, seed=42)(4, 22) for _ in range(2)]
+        self.assertEqual(x[0], x[1])
+        with self.assertRaises(ValueError):
+            prettyrandom.PrettyRandom(swap_probability=1.5)
+
+
+    def test_exact_composition(self) -> None:
+        """
+        Test case to ensure that strings with an exact composition contain exactly the requested characters per class.
+
+        Generates strings with half numbers and half uppercase letters and counts the characters of each class.
+        Asserts the exact counts, and that mismatching lengths and disabled classes are rejected.
+        """
+        generator = prettyrandom.PrettyRandom(exact_composition={'numbers': 6, 'uppercase': 6})
+        for _ in range(50):
+            x: str = generator(4, 12)
+            self.assertEqual([len(b) for b in x.split(" ")], [4, 4, 4])
+            self.assertEqual(sum(c.isdigit() for c in x), 6)
+            self.assertEqual(sum(c.isupper() for c in x), 6)
+        with self.assertRaises(ValueError):
+            generator(4, 16)
+        with self.assertRaises(ValueError):
+            prettyrandom.PrettyRandom(exact_composition={'lowercase': 6})
+
+
+    def test_reformat(self) -> None:
+        """
+        Test case to ensure that a space-grouped string can be reformatted into a hyphen-grouped one.
+
+        Reformats generated strings using an instance with a hyphen separator, keeping and changing the block size.
+        Asserts that the characters are preserved and that incompatible character sets are rejected.
+        """
+        hyphenated = prettyrandom.PrettyRandom(separator="-")
+        x: str = self.prettyrandom_generator(4, 16)
+        self.assertEqual(self.prettyrandom_generator.reformat_using(x, hyphenated), x.replace(" ", "-"))
+        y: str = self.prettyrandom_generator.reformat_using(x, hyphenated, blocksize=8)
+        self.assertEqual(self.prettyrandom_generator.reformat_using("NN88 GO4A", prettyrandom.PrettyRandom(separator="-", grouping=2)), "NN-88-GO-4A")
+        self.assertEqual(y, x.replace(" ", "")[:8] + "-" + x.replace(" ", "")[8:])
+        with self.assertRaises(ValueError):
+            self.prettyrandom_generator.reformat_using(x, prettyrandom.PrettyRandom(use_lowercase=True))
+
+
+    def test_shard(self) -> None:
+        """
+        Test case to ensure that codes of different shards have distinct, decodable shard segments.
+
+        Generates codes for several shards and decodes their shard identifiers.
+        Asserts that each code decodes to its shard, also with check characters, and that the leading segments of
+        different shards differ. Asserts that check characters overwriting the shard identifier are refused.
+        """
+        segments: set[str] = set()
+        for shard_id in [0, 1, 17, 255]:
+            generator = prettyrandom.PrettyRandom(shard_id=shard_id, shard_bits=8)
+            self.assertEqual(generator.shard_width, 2)
+            for _ in range(20):
+                x: str = generator(4, 22)
+                self.assertEqual(generator.shard_of(x), shard_id)
+            segments.add(x[:2])
+        self.assertEqual(len(segments), 4)
+        with self.assertRaises(ValueError):
+            prettyrandom.PrettyRandom(shard_id=256, shard_bits=8)
+
+        for options in [{'checksum': True}, {'checksum': 'iso7064', 'checksum_position': 2}, {'error_correction': 2, 'characters': "0123456789ABCDEFGHJKLMNPQRSTUVW"}, {'modulus_checksum': 97}, {'padding_character': "="}]:
+            with self.subTest(options=options):
+                generator = prettyrandom.PrettyRandom(shard_id=5, shard_bits=8, **options)
+                for _ in range(50): self.assertEqual(generator.shard_of(generator(2, 6)), 5)
+        self.assertRaises(ValueError, prettyrandom.PrettyRandom, shard_id=5, shard_bits=8, chained_checksums=True)
+        self.assertRaises(ValueError, prettyrandom.PrettyRandom(shard_id=5, shard_bits=8, checksum=True, checksum_position=0), 2, 6)
+        self.assertRaises(ValueError, prettyrandom.PrettyRandom(shard_id=5, shard_bits=8, error_correction=1), 2, 2)
+        self.assertRaises(ValueError, prettyrandom.PrettyRandom(shard_id=5, shard_bits=8, padding_character="="), 4, 3)
+
+
+    def test_block_initials(self) -> None:
+        """
+        Test case to ensure that the first characters of the blocks spell the configured acronym.
+
+        Generates strings with block initials and collects the first character of each block.
+        Asserts that the initials spell the acronym and that too short acronyms and unknown letters are rejected.
+        """
+        generator = prettyrandom.PrettyRandom(block_initials="ACME")
+        for _ in range(50):
+            self.assertEqual("".join(b[0] for b in generator(4, 16).split(" ")), "ACME")
+            self.assertEqual("".join(b[0] for b in generator(5, 12).split(" ")), "ACM")
+        with self.assertRaises(ValueError):
+            generator(4, 20)
+        with self.assertRaises(ValueError):
+            prettyrandom.PrettyRandom(block_initials="acme")
+
+
+    def test_fixtures(self) -> None:
+        """
+        Test case to ensure that fixtures generated from a seed are stable.
+
+        Generates fixtures twice with the same seed, once with a separate instance, and once with another seed.
+        Asserts that equal seeds yield equal fixtures and different seeds different ones.
+        """
+        fixtures: List[str] = self.prettyrandom_generator.generate_fixtures(7, 20, 4, 22)
+        self.assertEqual(len(fixtures), 20)
+        self.assertEqual(prettyrandom.PrettyRandom().generate_fixtures(7, 20, 4, 22), fixtures)
+        self.assertEqual(self.prettyrandom_generator.generate_fixtures(7, 20, 4, 22), fixtures)
+        self.assertNotEqual(self.prettyrandom_generator.generate_fixtures(8, 20, 4, 22), fixtures)
+
+
+    def test_interleave(self) -> None:
+        """
+        Test case to ensure that interleaved generators fill even and odd positions respectively.
+
+        Interleaves a numbers-only and a lowercase-only generator.
+        Asserts that even positions are numbers, odd positions lowercase letters, across block boundaries.
+        """
+        numbers = prettyrandom.PrettyRandom(use_uppercase=False)
+        letters = prettyrandom.PrettyRandom(use_numbers=False, use_lowercase=True, use_uppercase=False)
+        generator = prettyrandom.PrettyRandom(interleave=(numbers, letters))
+        for length in range(3, 30):
+            x: str = generator(3, length).replace(" ", "")
+            self.assertEqual(len(x), length)
+            self.assertTrue(all(c.isdigit() for c in x[0::2]))
+            self.assertTrue(all(c.islower() for c in x[1::2]))
+        with self.assertRaises(ValueError):
+            prettyrandom.PrettyRandom(interleave=(numbers, None))
+
+
+    def test_banned_blocks(self) -> None:
+        """
+        Test case to ensure that banned blocks never appear in the output.
+
+        Generates strings from a two-character alphabet where banned blocks are very likely, with and without checksums.
+        Asserts that none of the blocks is banned, and that empty banned blocks are rejected.
+        """
+        banned: List[str] = ["0000", "1111", "0101", "1010"]
+        for chained in [False, True]:
+            generator = prettyrandom.PrettyRandom(characters="01", banned_blocks=banned, chained_checksums=chained)
+            for _ in range(100):
+                for b in generator(4, 22).split(" "): self.assertNotIn(b, banned)
+        with self.assertRaises(ValueError):
+            prettyrandom.PrettyRandom(banned_blocks=[""])
+
+
+    def test_error_correction(self) -> None:
+        """
+        Test case to ensure that a single wrong character is detected and corrected.
+
+        Generates codes over a character set of prime size and replaces each character in turn.
+        Asserts that valid codes are accepted, level 2 restores the original code, and level 1 only detects the error,
+        also for the default character set, and that level 2 is refused where correction is not guaranteed.
+        """
+        characters: str = "0123456789ABCDEFGHJKLMNPQRSTUVW"  # 31 characters
+        for level in [1, 2]:
+            generator = prettyrandom.PrettyRandom(characters=characters, error_correction=level)
+            for _ in range(20):
+                x: str = generator(4, 22)
+                self.assertEqual(generator.correct(x), (x, True))
+                for i, c in enumerate(x):
+                    if c == " ": continue
+                    typo: str = x[:i] + ("1" if c != "1" else "2") + x[i + 1:]
+                    self.assertEqual(generator.correct(typo), (x, True) if level == 2 else (typo, False))
+
+        generator = prettyrandom.PrettyRandom(error_correction=1)
+        x = generator(4, 22)
+        for i, c in enumerate(x):
+            if c == " ": continue
+            self.assertEqual(generator.correct(x[:i] + ("1" if c != "1" else "2") + x[i + 1:])[1], False)
+        self.assertRaises(prettyrandom.ConfigurationError, prettyrandom.PrettyRandom, error_correction=2)
+        self.assertRaises(prettyrandom.InvalidLengthError, prettyrandom.PrettyRandom(characters=characters, error_correction=2), 4, 31)
+
+
+    def test_arch(self) -> None:
+        """
+        Test case to ensure that the arch rule rises to a peak and then falls.
+
+        Applies the arch rule for odd and even block sizes and computes the steps between character set indices.
+        Asserts that the steps are positive up to the peak and negative afterwards, including wrap-around.
+        """
+        generator = prettyrandom.PrettyRandom()
+        n: int = len(generator.character_set)
+        for blocksize in range(3, 12):
+            for start in ["A", "Y"]:
+                block: str = generator.arch(start, "B", blocksize)
+                self.assertEqual(len(block), blocksize)
+                indices: List[int] = [generator.character_set.index(c) for c in block]
+                steps: List[int] = [(b - a + n // 2) % n - n // 2 for a, b in zip(indices, indices[1:])]
+                peak: int = next(i for i, step in enumerate(steps) if step < 0)
+                self.assertGreater(peak, 0)
+                self.assertTrue(all(step > 0 for step in steps[:peak]))
+                self.assertTrue(all(step < 0 for step in steps[peak:]))
+        self.assertEqual(generator.arch("A", "B", 1), "A")
+
+
+    def test_font_profile(self) -> None:
+        """
+        Test case to ensure that a font profile restricts the output to approved characters.
+
+        Generates strings with the seven-segment profile from all character classes.
+        Asserts that only profile-approved characters are used and that unknown profiles are rejected.
+        """
+        generator = prettyrandom.PrettyRandom(use_lowercase=True, font_profile='seven_segment')
+        approved: set[str] = prettyrandom.FONT_PROFILES['seven_segment']
+        self.assertTrue(set(generator.character_set) <= approved)
+        for _ in range(100): self.assertTrue(set(generator(4, 22).replace(" ", "")) <= approved)
+        with self.assertRaises(ValueError):
+            prettyrandom.PrettyRandom(font_profile='dot_matrix')
+
+
+    def test_time_bucket(self) -> None:
+        """
+        Test case to ensure that codes generated within the same time window share their time segment.
+
+        Generates codes at several points in time using a fixed clock.
+        Asserts that codes within a window share a decodable time segment, that the next window differs,
+        and that the segments depend on the secret rather than on a fixed offset.
+        """
+        now: List[float] = [1_700_000_010.0]
+        generator = prettyrandom.PrettyRandom(time_bucket=datetime.timedelta(seconds=30), time_secret="s3cr3t", clock=lambda: now[0])
+        x: str = generator(4, 22).replace(" ", "")
+        now[0] += 10
+        y: str = generator(4, 22).replace(" ", "")
+        self.assertEqual(x[:generator.time_width], y[:generator.time_width])
+        self.assertEqual(generator.time_bucket_of(x), 1_700_000_010 // 30)
+        now[0] += 30
+        z: str = generator(4, 22).replace(" ", "")
+        self.assertNotEqual(x[:generator.time_width], z[:generator.time_width])
+        self.assertEqual(generator.time_bucket_of(z), generator.time_bucket_of(x) + 1)
+
+        # The segments of consecutive windows must not differ by a fixed offset, which would reveal all future windows
+        differences: set[int] = set()
+        for bucket in range(1_700_000_010 // 30, 1_700_000_010 // 30 + 10):
+            differences.add(generator.decode_int(generator.time_segment(bucket + 1)) - generator.decode_int(generator.time_segment(bucket)))
+            self.assertEqual(generator.time_bucket_of(generator.time_segment(bucket)), bucket)
+        self.assertGreater(len(differences), 1)
+        other = prettyrandom.PrettyRandom(time_bucket=datetime.timedelta(seconds=30), time_secret="other", clock=lambda: now[0])
+        self.assertNotEqual(other.time_segment(1_700_000_010 // 30), generator.time_segment(1_700_000_010 // 30))
+
+
+    def test_class_map(self) -> None:
+        """
+        Test case to ensure that the class map matches the significant characters of the string.
+
+        Generates strings from all character classes together with their class maps.
+        Asserts that the class map excludes separators and classifies every character correctly.
+        """
+        generator = prettyrandom.PrettyRandom(use_lowercase=True)
+        for _ in range(50):
+            x, classes = generator.generate_with_class_map(4, 22)
+            chars: str = x.replace(" ", "")
+            self.assertEqual(len(classes), len(chars))
+            for c, name in zip(chars, classes):
+                self.assertEqual(name, 'numbers' if c.isdigit() else 'lowercase' if c.islower() else 'uppercase')
+        self.assertEqual(generator.character_class("="), 'other')
+
+
+    def test_sanitize(self) -> None:
+        """
+        Test case to ensure that no block contains whitespace or the separator character.
+
+        Generates strings with a rule that produces stray whitespace and separator characters.
+        Asserts that every block keeps its size and contains neither whitespace nor the separator.
+        """
+        generator = prettyrandom.PrettyRandom(separator="-")
+        generator.register_rule('broken', lambda rng, char1, char2, blocksize: (" " + char1 + "-" + char2 * blocksize)[:blocksize])
+        generator.use_rules('broken')
+        for _ in range(50):
+            blocks: List[str] = generator(4, 22).split("-")
+            self.assertEqual([len(b) for b in blocks], [4, 4, 4, 4, 4, 2])
+            for b in blocks: self.assertFalse(any(c.isspace() for c in b))
+
+
+    def test_endianness(self) -> None:
+        """
+        Test case to ensure that numbers round-trip under both endianness settings.
+
+        Encodes numbers as codes and decodes them again, with big and little endianness.
+        Asserts the round trip, the digit order, and that shard identifiers still decode.
+        """
+        for endianness in ['big', 'little']:
+            generator = prettyrandom.PrettyRandom(use_uppercase=False, endianness=endianness)
+            for value in [0, 7, 1234, 99999999]:
+                self.assertEqual(generator.to_int(generator.from_int(value, 4, 8)), value)
+            self.assertEqual(generator.from_int(1234, 4, 8), "0000 1234" if endianness == 'big' else "4321 0000")
+            generator = prettyrandom.PrettyRandom(shard_id=300, shard_bits=12, endianness=endianness)
+            self.assertEqual(generator.shard_of(generator(4, 22)), 300)
+        with self.assertRaises(ValueError):
+            prettyrandom.PrettyRandom(endianness='middle')
+
+
+    def test_checkerboard(self) -> None:
+        """
+        Test case to ensure that the checkerboard alternates across block boundaries.
+
+        Generates strings in checkerboard mode with an odd block size.
+        Asserts that all even positions share one character and all odd positions another one.
+        """
+        generator = prettyrandom.PrettyRandom(checkerboard=True)
+        for length in range(3, 30):
+            x: str = generator(3, length)
+            self.assertEqual([len(b) for b in x.split(" ")], [3] * (length // 3) + ([length % 3] if length % 3 else []))
+            chars: str = x.replace(" ", "")
+            self.assertEqual(len(set(chars[0::2])), 1)
+            self.assertEqual(len(set(chars[1::2])), 1)
+            self.assertNotEqual(chars[0], chars[1])
+
+
+    def test_min_transitions(self) -> None:
+        """
+        Test case to ensure that strings have at least the configured number of transitions.
+
+        Generates strings with a transition floor and counts consecutive differing characters, skipping separators.
+        Asserts that the floor holds and that an impossible floor is rejected.
+        """
+        generator = prettyrandom.PrettyRandom(min_transitions=12)
+        for _ in range(50):
+            x: str = generator(4, 22).replace(" ", "")
+            self.assertGreaterEqual(sum(a != b for a, b in zip(x, x[1:])), 12)
+        with self.assertRaises(ValueError):
+            generator(4, 12)
+
+
+    def test_recipe(self) -> None:
+        """
+        Test case to ensure that a recipe reproduces the identical string.
+
+        Generates strings with recipes and regenerates them, also with a separate instance and with a Bloom filter.
+        Asserts that the reproduced strings are identical, that the plan lists the blocks,
+        and that malformed recipes and different configurations are rejected.
+        """
+        for _ in range(20):
+            x, recipe = self.prettyrandom_generator.generate_with_recipe(4, 22)
+            self.assertEqual(self.prettyrandom_generator.generate_from_recipe(recipe), x)
+            self.assertEqual(prettyrandom.PrettyRandom().generate_from_recipe(recipe), x)
+        with self.assertRaises(ValueError):
+            self.prettyrandom_generator.generate_from_recipe("pr1-xyz-4-22")
+
+        generator = prettyrandom.PrettyRandom(bloom_expected=1000)
+        for _ in range(20):
+            x, recipe = generator.generate_with_recipe(4, 22)
+            self.assertEqual(generator.generate_from_recipe(recipe), x)
+        self.assertEqual(generator.generate_from_recipe(recipe), x)
+
+        # The plan lists the rule and characters of every block and detects a different configuration
+        x, recipe = prettyrandom.PrettyRandom(rule_selection='repeat', swap_probability=0).generate_with_recipe(4, 8)
+        plan: List[List[str]] = json.loads(base64.urlsafe_b64decode(recipe.split("-", 4)[4] + "=="))
+        self.assertEqual([rule for rule, _, _ in plan], ['repeat', 'repeat'])
+        self.assertEqual(x, " ".join(char1 * 4 for _, char1, _ in plan))
+        self.assertRaises(ValueError, prettyrandom.PrettyRandom(rule_selection='pairs').generate_from_recipe, recipe)
+        self.assertEqual(self.prettyrandom_generator.generate_from_recipe("pr1-" + recipe.split("-")[1] + "-4-8"), self.prettyrandom_generator.replay(int(recipe.split("-")[1], 16), 4, 8)[0])
+
+
+    def test_forbidden_bigrams(self) -> None:
+        """
+        Test case to ensure that forbidden bigrams never appear, including across block boundaries.
+
+        Generates strings from a small alphabet with block size 1, so that every bigram spans a block boundary.
+        Asserts that none of the forbidden bigrams appears in the significant characters.
+        """
+        generator = prettyrandom.PrettyRandom(characters="0123", forbidden_bigrams=["00", "12"])
+        for blocksize in [1, 3]:
+            for _ in range(50):
+                x: str = generator(blocksize, 12).replace(" ", "")
+                self.assertNotIn("00", x)
+                self.assertNotIn("12", x)
+        with self.assertRaises(ValueError):
+            prettyrandom.PrettyRandom(forbidden_bigrams=["000"])
+
+
+    def test_generate_n(self) -> None:
+        """
+        Test case to ensure that a batch is unique and, if requested, sorted.
+
+        Generates batches with and without sorting and with a custom sort key.
+        Asserts uniqueness, the order, and that impossible batch sizes fail.
+        """
+        batch: List[str] = self.prettyrandom_generator.generate_n(200, 4, 8, sort=True)
+        self.assertEqual(len(set(batch)), 200)
+        self.assertEqual(batch, sorted(batch))
+        batch = self.prettyrandom_generator.generate_n(50, 4, 8, sort=True, key=lambda x: x[::-1])
+        self.assertEqual(batch, sorted(batch, key=lambda x: x[::-1]))
+        with self.assertRaises(RuntimeError):
+            prettyrandom.PrettyRandom(characters="01").generate_n(5, 2, 2)
+
+
+    def test_case_ratio(self) -> None:
+        """
+        Test case to ensure that the fraction of uppercase letters approximates the case ratio.
+
+        Generates many strings with a case ratio of 80% and counts uppercase and lowercase letters.
+        Asserts that the uppercase fraction is close to the ratio and that invalid configurations are rejected.
+        """
+        generator = prettyrandom.PrettyRandom(use_lowercase=True, case_ratio=0.8)
+        x: str = "".join(generator(4, 22) for _ in range(500))
+        upper: int = sum(c.isupper() for c in x)
+        lower: int = sum(c.islower() for c in x)
+        self.assertAlmostEqual(upper / (upper + lower), 0.8, delta=0.05)
+        with self.assertRaises(ValueError):
+            prettyrandom.PrettyRandom(case_ratio=0.8)
+        with self.assertRaises(ValueError):
+            prettyrandom.PrettyRandom(use_lowercase=True, case_ratio=1.2)
+
+
+    def test_timestamp(self) -> None:
+        """
+        Test case to ensure that codes past their time to live are reported as expired.
+
+        Generates a code with an embedded timestamp using a fixed clock and advances the clock.
+        Asserts that the timestamp decodes and that the code expires only after its time to live.
+        """
+        now: List[float] = [1_700_000_000.0]
+        generator = prettyrandom.PrettyRandom(timestamp_block=1, clock=lambda: now[0])
+        x: str = generator(4, 22)
+        self.assertEqual(generator.timestamp_of(x), 1_700_000_000)
+        now[0] += 3000
+        self.assertFalse(generator.is_expired(x, datetime.timedelta(hours=1)))
+        now[0] += 1000
+        self.assertTrue(generator.is_expired(x, datetime.timedelta(hours=1)))
+        with self.assertRaises(ValueError):
+            generator(4, 8)
+
+
+    def test_parallel(self) -> None:
+        """
+        Test case to ensure that parallel generation yields the requested number of unique strings.
+
+        Generates batches across several worker processes, with and without a seed, and with a rule that cannot be pickled.
+        Asserts the count and uniqueness, that seeded batches are reproducible, and that the Bloom filter records the strings
+        so that later batches do not repeat them.
+        """
+        batch: List[str] = self.prettyrandom_generator.generate_n_parallel(1000, 4, 8, workers=3)
+        self.assertEqual(len(batch), 1000)
+        self.assertEqual(len(set(batch)), 1000)
+        seeded: List[str] = self.prettyrandom_generator.generate_n_parallel(100, 4, 8, workers=2, seed=5)
+        self.assertEqual(self.prettyrandom_generator.generate_n_parallel(100, 4, 8, workers=2, seed=5), seeded)
+
+        generator = prettyrandom.PrettyRandom(bloom_expected=1000)
+        generator.register_rule('mirror', lambda rng, char1, char2, blocksize: (char1 + char2) * (blocksize // 2) + char1 * (blocksize % 2))
+        batch = generator.generate_n_parallel(200, 4, 8, workers=2, seed=5)
+        self.assertEqual(len(set(batch)), 200)
+        self.assertTrue(all(generator.significant(code) in generator.bloom for code in batch))
+        self.assertTrue(set(batch).isdisjoint(generator.generate_n_parallel(200, 4, 8, workers=2, seed=5)))
+
+
+    def test_checksum_position(self) -> None:
+        """
+        Test case to ensure that a check character at an interior position validates and detects changes.
+
+        Generates codes with the check character at the trailing and at an interior position and changes each character.
+        Asserts that valid codes validate, every single-character change is detected, and out-of-range positions are rejected.
+        """
+        for position in [-1, 5]:
+            generator = prettyrandom.PrettyRandom(checksum=True, checksum_position=position)
+            for _ in range(20):
+                x: str = generator(4, 22)
+                self.assertTrue(generator.validate(x))
+                for i, c in enumerate(x):
+                    if c == " ": continue
+                    self.assertFalse(generator.validate(x[:i] + ("1" if c != "1" else "2") + x[i + 1:]))
+        with self.assertRaises(ValueError):
+            prettyrandom.PrettyRandom(checksum=True, checksum_position=30)(4, 22)
+
+
+    def test_markov(self) -> None:
+        """
+        Test case to ensure that a Markov model keeps characters in the alphabet and skews them toward the sample.
+
+        Generates strings with a model built from a sample, part of which lies outside the character set.
+        Asserts that all characters belong to the character set and that the sample's characters dominate.
+        """
+        generator = prettyrandom.PrettyRandom(markov_order=2, markov_sample="BANANA banana 42" * 10)
+        x: str = "".join(generator(4, 22).replace(" ", "") for _ in range(200))
+        self.assertTrue(set(x) <= set(generator.character_set))
+        self.assertGreater(sum(x.count(c) for c in "ABN42") / len(x), 0.5)
+        with self.assertRaises(ValueError):
+            prettyrandom.PrettyRandom(markov_sample="banana")
+
+
+    def test_non_monotonic(self) -> None:
+        """
+        Test case to ensure that fully sorted outputs are rejected.
+
+        Generates short strings from a small alphabet, where sorted outputs are frequent, with non_monotonic enabled.
+        Asserts that no output is sorted ascending or descending, and that too short lengths are rejected.
+        """
+        generator = prettyrandom.PrettyRandom(characters="0123", non_monotonic=True)
+        for _ in range(200):
+            x: List[str] = list(generator(2, 4).replace(" ", ""))
+            self.assertNotEqual(x, sorted(x))
+            self.assertNotEqual(x, sorted(x, reverse=True))
+        with self.assertRaises(ValueError):
+            generator(2, 2)
+
+
+    def test_composite(self) -> None:
+        """
+        Test case to ensure that a composite rule only produces output of its member rules.
+
+        Registers a composite of the repeat and alternate rules and applies it many times.
+        Asserts that every block is a repeat or alternate pattern and that invalid composites are rejected.
+        """
+        generator = prettyrandom.PrettyRandom(swap_probability=0)
+        generator.register_composite("fancy", {'repeat': 1, 'alternate': 3})
+        self.assertIn("fancy", generator.rules)
+        blocks: set[str] = {generator.apply_rule(generator.rules["fancy"], "A", "B", 4) for _ in range(200)}
+        self.assertEqual(blocks, {"AAAA", "ABAB"})
+        with self.assertRaises(ValueError):
+            generator.register_composite("fancy", {'repeat': 1})
+        with self.assertRaises(ValueError):
+            generator.register_composite("other", {'unknown': 1})
+
+
+    def test_byte_budget(self) -> None:
+        """
+        Test case to ensure that strings never exceed the UTF-8 byte budget.
+
+        Generates strings from an alphabet mixing one-, two- and three-byte characters for several budgets.
+        Asserts that the returned byte length is exact and within the budget, and that the string does not end with a separator.
+        """
+        generator = prettyrandom.PrettyRandom(characters="ABαβ漢字")
+        for max_bytes in range(3, 40):
+            x, size = generator.generate_within_bytes(4, max_bytes)
+            self.assertEqual(len(x.encode("utf-8")), size)
+            self.assertLessEqual(size, max_bytes)
+            self.assertFalse(x.endswith(" "))
+        self.assertEqual(prettyrandom.PrettyRandom().generate_within_bytes(4, 3)[1], 3)
+        self.assertRaises(ValueError, prettyrandom.PrettyRandom(characters="漢字").generate_within_bytes, 4, 2)
+
+
+    def test_obfuscated_sequence(self) -> None:
+        """
+        Test case to ensure that obfuscated sequence codes recover their counter and look scrambled.
+
+        Issues consecutive codes and recovers their counter values, also with a second instance sharing the key.
+        Asserts the round trip, that codes are unique and not ordered like the counter, and that other keys do not recover it.
+        """
+        generator = prettyrandom.PrettyRandom(sequence_key="k3y")
+        codes: List[str] = [generator.generate_sequence(4, 8) for _ in range(100)]
+        self.assertEqual([generator.sequence_of(x) for x in codes], list(range(100)))
+        self.assertEqual(prettyrandom.PrettyRandom(sequence_key="k3y").sequence_of(codes[42]), 42)
+        self.assertNotEqual(prettyrandom.PrettyRandom(sequence_key="other").sequence_of(codes[42]), 42)
+        self.assertEqual(len(set(codes)), 100)
+        self.assertNotEqual(codes, sorted(codes))
+        self.assertGreater(sum(a[:4] != b[:4] for a, b in zip(codes, codes[1:])), 90)
+
+
+    def test_near(self) -> None:
+        """
+        Test case to ensure that generated variants have exactly the requested Levenshtein distance.
+
+        Generates variants of a code for every achievable distance.
+        Asserts the computed distance, that separators are kept, and that unachievable distances are rejected.
+        """
+        base: str = self.prettyrandom_generator(4, 12)
+        for distance in range(13):
+            with self.subTest(distance=distance):
+                variant: str = self.prettyrandom_generator.generate_near(base, distance)
+                self.assertEqual(prettyrandom.PrettyRandom.levenshtein(base.replace(" ", ""), variant.replace(" ", "")), distance)
+                self.assertEqual([i for i, x in enumerate(variant) if x == " "], [i for i, x in enumerate(base) if x == " "])
+        self.assertEqual(prettyrandom.PrettyRandom.levenshtein("kitten", "sitting"), 3)
+        self.assertRaises(ValueError, self.prettyrandom_generator.generate_near, base, 13)
+        self.assertRaises(ValueError, self.prettyrandom_generator.generate_near, base, -1)
+        single = prettyrandom.PrettyRandom(characters="A")
+        self.assertRaises(prettyrandom.InvalidLengthError, single.generate_near, "AAAA", 1)
+        self.assertEqual(single.generate_near("AAAA", 0), "AAAA")
+        self.assertEqual(single.levenshtein(single.generate_near("AB", 1), "AB"), 1)
+
+
+    def test_fingerprint(self) -> None:
+        """
+        Test case to ensure that fingerprints identify the effective configuration.
+
+        Constructs equivalent generators in different ways, and generators differing in a single option.
+        Asserts that equivalent generators share a fingerprint and different ones do not.
+        """
+        fingerprint: str = self.prettyrandom_generator.fingerprint()
+        self.assertEqual(prettyrandom.PrettyRandom(characters="0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ").fingerprint(), fingerprint)
+        self.assertEqual(prettyrandom.PrettyRandom(use_lowercase=False, separator=" ").fingerprint(), fingerprint)
+        self.assertNotEqual(prettyrandom.PrettyRandom(use_lowercase=True).fingerprint(), fingerprint)
+        self.assertNotEqual(prettyrandom.PrettyRandom(separator="-").fingerprint(), fingerprint)
+        self.assertNotEqual(prettyrandom.PrettyRandom(swap_probability=0.25).fingerprint(), fingerprint)
+
+        first = prettyrandom.PrettyRandom()
+        second = prettyrandom.PrettyRandom()
+        first.register_composite("fancy", {'alternate': 3, 'pairs': 1})
+        self.assertNotEqual(first.fingerprint(), second.fingerprint())
+        second.register_composite("fancy", {'alternate': 1, 'pairs': 3})
+        self.assertNotEqual(first.fingerprint(), second.fingerprint())
+        self.assertEqual(first.fingerprint(), first.clone().fingerprint())
+
+        self.assertEqual(prettyrandom.PrettyRandom(exclude_ambiguous=True).fingerprint(), prettyrandom.PrettyRandom(exclude="0O1lI5S8B").fingerprint())
+        listed = prettyrandom.PrettyRandom(rules=['alternate', 'pairs'])
+        used = prettyrandom.PrettyRandom()
+        used.use_rules('alternate', 'pairs')
+        self.assertEqual(listed.fingerprint(), used.fingerprint())
+
+        listed.register_rule("custom", lambda rng, char1, char2, blocksize: char1 * blocksize)
+        used.register_rule("custom", lambda rng, char1, char2, blocksize: char2 * blocksize)
+        self.assertNotEqual(listed.fingerprint(), used.fingerprint())
+
+
+    def test_alternating_scripts(self) -> None:
+        """
+        Test case to ensure that blocks alternate between two scripts.
+
+        Generates strings with Latin and Greek blocks of various blocksizes and lengths.
+        Asserts that every block is drawn entirely from its script and that no characters outside both scripts appear.
+        """
+        latin: str = "ABCDEFGHJKMNPRST"
+        greek: str = "αβγδεζηθλμξπσφψω"
+        generator = prettyrandom.PrettyRandom(alternating_scripts=(latin, greek))
+        for blocksize in range(1, 6):
+            for length in range(blocksize, 30):
+                with self.subTest(blocksize=blocksize, length=length):
+                    output: str = generator(blocksize, length)
+                    self.assertEqual(len(output.replace(" ", "")), length)
+                    for i, block in enumerate(output.split(" ")):
+                        self.assertTrue(set(block) <= set(latin if i % 2 == 0 else greek))
+        self.assertRaises(ValueError, prettyrandom.PrettyRandom, alternating_scripts=(latin,))
+        self.assertRaises(ValueError, prettyrandom.PrettyRandom, alternating_scripts=(latin, greek), checkerboard=True)
+
+
+    def test_bloom(self) -> None:
+        """
+        Test case to ensure that codes issued with a Bloom filter are not repeated.
+
+        Issues codes, then replays the same random source against the filter and a restored copy of it.
+        Asserts that issued codes are added, that identical candidates are rejected afterwards,
+        and that seeded generation such as fixtures stays deterministic.
+        """
+        generator = prettyrandom.PrettyRandom(bloom_expected=1000, bloom_false_positive_rate=0.001, seed=7)
+        codes: List[str] = [generator(4, 8) for _ in range(200)]
+        self.assertEqual(len(set(codes)), 200)
+        self.assertTrue(all(code.replace(" ", "") in generator.bloom for code in codes))
+
+        # The same seed proposes the same candidates again, which must all be rejected now
+        generator.rng = random.Random(7)
+        self.assertTrue(set(generator(4, 8) for _ in range(200)).isdisjoint(codes))
+
+        # Deterministic generation is not filtered, so it reproduces its strings
+        u: str = "123e4567-e89b-12d3-a456-426614174000"
+        self.assertEqual(generator.from_uuid(u, 4, 25), generator.from_uuid(u, 4, 25))
+        self.assertEqual(generator.generate_fixtures(1, 2, 4, 8), generator.generate_fixtures(1, 2, 4, 8))
+
+        restored = prettyrandom.PrettyRandom(seed=7)
+        restored.bloom = prettyrandom.BloomFilter.from_bytes(generator.bloom.to_bytes())
+        self.assertTrue(set(restored(4, 8) for _ in range(200)).isdisjoint(codes))
+        self.assertRaises(ValueError, prettyrandom.BloomFilter, 0, 0.01)
+        self.assertRaises(ValueError, prettyrandom.BloomFilter.from_bytes, b"corrupt")
+
+
+    def test_modulus_checksum(self) -> None:
+        """
+        Test case to ensure that the trailing modulus checksum matches the numeric value of the code.
+
+        Generates strings with a modulus checksum and changes single characters.
+        Asserts that generated strings verify and that value-changing edits are detected.
+        """
+        generator = prettyrandom.PrettyRandom(modulus_checksum=97)
+        for length in range(4, 30):
+            with self.subTest(length=length):
+                output: str = generator(4, length)
+                chars: str = output.replace(" ", "")
+                self.assertTrue(generator.verify_modulus(output))
+                self.assertEqual(generator.decode_int(chars[-2:]), generator.decode_int(chars[:-2]) % 97)
+
+                # Change one character by a step not divisible by 97
+                position: int = generator.rng.randrange(len(chars) - 2)
+                index: int = generator.character_set.index(chars[position])
+                edited: str = chars[:position] + generator.character_set[(index + 1) % 36] + chars[position + 1:]
+                self.assertFalse(generator.verify_modulus(edited))
+        self.assertRaises(ValueError, prettyrandom.PrettyRandom, modulus_checksum=1)
+        self.assertRaises(ValueError, prettyrandom.PrettyRandom, modulus_checksum=97, checksum=True)
+
+
+    def test_readability(self) -> None:
+        """
+        Test case to ensure that strings below the minimum readability score are regenerated.
+
+        Scores hand-picked codes and generates strings with a minimum readability.
+        Asserts that repetitive and sequential codes score low and that all generated strings meet the threshold.
+        """
+        self.assertEqual(self.prettyrandom_generator.readability_score("7A2K Q9M4"), 1.0)
+        self.assertEqual(self.prettyrandom_generator.readability_score("AAAA AAAA"), 0.25)
+        self.assertLess(self.prettyrandom_generator.readability_score("1234 5678"), 0.75)
+
+        generator = prettyrandom.PrettyRandom(min_readability=0.8)
+        scores: List[float] = [self.prettyrandom_generator.readability_score(self.prettyrandom_generator(4, 12)) for _ in range(200)]
+        self.assertTrue(any(score < 0.8 for score in scores))
+        for _ in range(200):
+            self.assertGreaterEqual(generator.readability_score(generator(4, 12)), 0.8)
+        self.assertRaises(ValueError, prettyrandom.PrettyRandom, min_readability=1.5)
+
+
+    def test_rng(self) -> None:
+        """
+        Test case to ensure that an injected random source makes the output reproducible.
+
+        Generates strings with two generators using identically seeded random sources.
+        Asserts that the outputs are identical, that the injected source is used, and that seed and source are exclusive.
+        """
+        first = prettyrandom.PrettyRandom(rng=random.Random(42))
+        second = prettyrandom.PrettyRandom(rng=random.Random(42))
+        self.assertEqual([first(4, 16) for _ in range(50)], [second(4, 16) for _ in range(50)])
+
+        source = random.Random(42)
+        generator = prettyrandom.PrettyRandom(rng=source)
+        self.assertIs(generator.rng, source)
+        state = source.getstate()
+        generator(4, 16)
+        self.assertNotEqual(source.getstate(), state)
+        self.assertEqual(generator.fingerprint(), self.prettyrandom_generator.fingerprint())
+        self.assertRaises(ValueError, prettyrandom.PrettyRandom, rng=random.Random(), seed=1)
+
+
+    def test_zerofill(self) -> None:
+        """
+        Test case to ensure that the zerofill rule pads a run of the second character with the first to exactly the blocksize.
+
+        Applies the rule to letters, numbers and sign characters for blocksizes 1 through 8.
+        Asserts the length, the allowed characters, and that the block is a single run padded on one side.
+        """
+        generator = prettyrandom.PrettyRandom(characters="0AZ7-+")
+        for blocksize in range(1, 9):
+            for char1, char2 in ["0A", "AZ", "Z7", "-+", "+0"]:
+                with self.subTest(blocksize=blocksize, char1=char1, char2=char2):
+                    blocks: set[str] = {generator.zerofill(char1, char2, blocksize) for _ in range(200)}
+                    runs: range = range(1, max(1, blocksize - 1) + 1)
+                    expected: set[str] = {char1 * (blocksize - run) + char2 * run for run in runs} | {char2 * run + char1 * (blocksize - run) for run in runs}
+                    self.assertEqual(blocks, expected)
+
+
+    def test_outliers(self) -> None:
+        """
+        Test case to ensure that the number of outliers can be configured as a count or a ratio.
+
+        Applies the outlier rule with counts, ratios and the default, and configures invalid values.
+        Asserts the number of outliers, that every block keeps a majority character, and that invalid values are refused.
+        """
+        for outliers, blocksize, expected in [(1, 4, 1), (2, 4, 2), (3, 8, 3), (5, 4, 3), (0.25, 8, 2), (0.5, 6, 3), (1.0, 4, 3), (0.1, 4, 1), (2, 1, 1)]:
+            with self.subTest(outliers=outliers, blocksize=blocksize):
+                generator = prettyrandom.PrettyRandom(outliers=outliers)
+                for _ in range(20):
+                    block: str = generator.outlier("A", "B", blocksize)
+                    self.assertEqual(len(block), blocksize)
+                    self.assertEqual(block.count("B"), expected)
+        self.assertGreater(prettyrandom.PrettyRandom(outliers=2).entropy(8, 16), prettyrandom.PrettyRandom().entropy(8, 16))
+        for outliers in [0, -1, 0.0, 1.5, True, "2", None]:
+            with self.subTest(outliers=outliers):
+                self.assertRaises(prettyrandom.ConfigurationError, prettyrandom.PrettyRandom, outliers=outliers)
+
+
+    def test_rules(self) -> None:
+        """
+        Test case to ensure that every built-in rule renders blocks of exactly the blocksize from the character set.
+
+        Applies every rule with random characters for blocksizes 1 through 9, for several alphabets including
+        letters only, digits only, symbols and non-ASCII characters.
+        Asserts the length of every block and that it only contains characters of the character set.
+        """
+        generators: List[prettyrandom.PrettyRandom] = [
+            prettyrandom.PrettyRandom(),
+            prettyrandom.PrettyRandom(use_numbers=False, use_lowercase=True, outliers=0.5),
+            prettyrandom.PrettyRandom(use_uppercase=False, outliers=3),
+            prettyrandom.PrettyRandom(charset='crockford', exclude_ambiguous=True),
+            prettyrandom.PrettyRandom(use_numbers=False, use_uppercase=False, use_symbols=True),
+            prettyrandom.PrettyRandom(characters="äöüß€漢字")
+        ]
+        for generator in generators:
+            for name in generator.rule_names():
+                for blocksize in range(1, 10):
+                    with self.subTest(characters="".join(generator.character_set), rule=name, blocksize=blocksize):
+                        for _ in range(20):
+                            char1, char2 = generator.rng.choice(generator.character_set), generator.rng.choice(generator.character_set)
+                            block: str = generator.rules[name](char1, char2, blocksize)
+                            self.assertEqual(len(block), blocksize)
+                            self.assertTrue(set(block) <= set(generator.character_set))
+
+
+    def test_pairs(self) -> None:
+        """
+        Test case to ensure that the pairs rule tiles the AABB pattern to exactly the blocksize.
+
+        Applies the rule for small blocksizes with partial pairs and for large blocksizes.
+        Asserts the exact tiling without partial garbage at either end.
+        """
+        expected: Dict[int, str] = {1: "A", 2: "AA", 3: "AAB", 4: "AABB", 5: "AABBA", 16: "AABBAABBAABBAABB"}
+        for blocksize, block in expected.items():
+            with self.subTest(blocksize=blocksize):
+                self.assertEqual(self.prettyrandom_generator.pairs("A", "B", blocksize), block)
+        for blocksize in range(1, 33):
+            with self.subTest(blocksize=blocksize):
+                self.assertEqual(self.prettyrandom_generator.pairs("7", "X", blocksize), ("77XX" * 8)[:blocksize])
+
+
+    def test_symbols(self) -> None:
+        """
+        Test case to ensure that symbols and custom non-ASCII alphabets are supported.
+
+        Generates strings with symbols enabled, with symbols only, and with a custom non-ASCII alphabet.
+        Asserts that only characters of the respective set appear and that lengths count characters, not bytes.
+        """
+        generator = prettyrandom.PrettyRandom(use_symbols=True)
+        outputs: str = "".join(generator(4, 16).replace(" ", "") for _ in range(100))
+        self.assertTrue(set(outputs) & set("!@#$%^&*"))
+        self.assertTrue(set(outputs) <= set("0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ!@#$%^&*"))
+        self.assertEqual(generator.character_class("%"), 'symbols')
+
+        symbols = prettyrandom.PrettyRandom(use_numbers=False, use_uppercase=False, use_symbols=True)
+        self.assertEqual(symbols.character_set, sorted("!@#$%^&*"))
+
+        custom = prettyrandom.PrettyRandom(characters="äöüß€漢字")
+        for blocksize in range(1, 6):
+            with self.subTest(blocksize=blocksize):
+                self.assertTrue(set(custom.outlier("漢", "€", blocksize)) <= {"漢", "€"})
+                self.assertEqual(len(custom.outlier("漢", "€", blocksize)), blocksize)
+                self.assertEqual(len(custom(blocksize, 12).replace(" ", "")), 12)
+
+
+    def test_register_rule(self) -> None:
+        """
+        Test case to ensure that custom rules can be registered and are checked for their length.
+
+        Registers a custom rule, tries to replace built-in rules, and registers a rule returning wrong lengths.
+        Asserts that the rule is listed and used, that built-ins are protected, and that wrong lengths raise an error.
+        """
+        generator = prettyrandom.PrettyRandom()
+        generator.register_rule("sandwich", lambda rng, char1, char2, blocksize: (char1 + char2 * (blocksize - 2) + char1)[:blocksize])
+        self.assertIn("sandwich", generator.rule_names())
+        self.assertEqual(generator.rule_names()[:7], ['repeat', 'alternate', 'pairs', 'outlier', 'zerofill', 'base64ish', 'arch'])
+
+        alternate: prettyrandom.Rule = lambda rng, char1, char2, blocksize: generator.alternate(char1, char2, blocksize)
+        self.assertRaises(ValueError, generator.register_rule, "", alternate)
+        self.assertRaises(ValueError, generator.register_rule, "repeat", alternate)
+        self.assertRaises(ValueError, generator.register_rule, "sandwich", alternate)
+        generator.register_rule("repeat", alternate, overwrite=True)
+        self.assertEqual(generator.rule_names().count("repeat"), 1)
+
+        # Only the custom rule remains, so every block has to be a sandwich
+        generator.use_rules('sandwich')
+        for block in generator(5, 20).split(" "):
+            self.assertEqual(block[0], block[-1])
+
+        # Rules receive the random source of the generator, so seeded generation covers them
+        generator.register_rule("coin", lambda rng, char1, char2, blocksize: "".join(rng.choice((char1, char2)) for _ in range(blocksize)))
+        generator.use_rules('coin')
+        self.assertEqual(generator.generate_fixtures(3, 5, 8, 16), generator.generate_fixtures(3, 5, 8, 16))
+        self.assertEqual(generator.clone(4)(8, 16), generator.clone(4)(8, 16))
+
+        broken = prettyrandom.PrettyRandom()
+        broken.register_rule("short", lambda rng, char1, char2, blocksize: char1)
+        broken.use_rules("short")
+        self.assertRaises(ValueError, broken, 4, 8)
+
+
+    def test_rule_selection(self) -> None:
+        """
+        Test case to ensure that the rule selection restricts and biases the chosen rules.
+
+        Selects a single rule, then weighted rules, and counts the chosen rules.
+        Asserts the restriction, the normalized weights, the approximate frequencies and that unknown rules are rejected.
+        """
+        generator = prettyrandom.PrettyRandom(rule_selection="repeat")
+        for _ in range(50):
+            self.assertTrue(all(len(set(block)) == 1 for block in generator(4, 16).split(" ")))
+
+        generator.set_rule_selection({'repeat': 7, 'pairs': 3})
+        self.assertEqual(generator.rule_weights, {'repeat': 0.7, 'pairs': 0.3})
+        chosen: List = [generator.random_rule() for _ in range(10000)]
+        self.assertEqual(set(chosen), {generator.repeat, generator.pairs})
+        self.assertAlmostEqual(chosen.count(generator.repeat) / 10000, 0.7, delta=0.03)
+
+        generator.set_rule_selection(None)
+        self.assertEqual(len(set(generator.random_rule() for _ in range(1000))), 8)
+        self.assertRaises(ValueError, prettyrandom.PrettyRandom, rule_selection="unknown")
+        self.assertRaises(ValueError, prettyrandom.PrettyRandom, rule_selection={'repeat': 0})
+        self.assertRaises(ValueError, prettyrandom.PrettyRandom, rule_selection={'repeat': -1, 'pairs': 2})
+
+
+    def test_secure(self) -> None:
+        """
+        Test case to ensure that secure generators draw from the system's entropy source.
+
+        Generates strings with a secure generator and its clone, and simulates a failing entropy source.
+        Asserts the random source, varying outputs, rejected seeds and a GenerationError from every entry point on entropy failure.
+        """
+        generator = prettyrandom.PrettyRandom(secure=True)
+        self.assertIsInstance(generator.rng, random.SystemRandom)
+        self.assertIsInstance(generator.clone().rng, random.SystemRandom)
+        self.assertEqual(len(set(generator(4, 16) for _ in range(100))), 100)
+        self.assertRaises(ValueError, prettyrandom.PrettyRandom, secure=True, seed=1)
+
+        # Detailed output must not derive the string from a seed drawn from the entropy source
+        with mock.patch.object(generator, 'replay', side_effect=AssertionError("seeded generation")):
+            result = generator.generate_detailed(4, 16)
+        self.assertEqual(result.recipe, "")
+        self.assertEqual("".join(block.text for block in result.blocks), result.output.replace(" ", ""))
+        self.assertTrue(all(block.rule for block in result.blocks))
+        self.assertIsInstance(generator.rng, prettyrandom.SecureRandom)
+        self.assertRaises(prettyrandom.ConfigurationError, generator.generate_with_recipe, 4, 16)
+
+        with mock.patch('random._urandom', side_effect=OSError("no entropy")):
+            self.assertRaises(prettyrandom.GenerationError, generator, 4, 16)
+            self.assertRaises(prettyrandom.GenerationError, generator.generate_from_template, "AAAA-9999")
+            self.assertRaises(prettyrandom.GenerationError, generator.generate_range, 4, 8, 16)
+            self.assertRaises(prettyrandom.GenerationError, generator.generate_detailed, 4, 16)
+            self.assertRaises(prettyrandom.GenerationError, generator.generate_to, io.StringIO(), 4, 16)
+            self.assertRaises(prettyrandom.GenerationError, generator.reader(4).read, 16)
+
+
+    def test_exclude_ambiguous(self) -> None:
+        """
+        Test case to ensure that ambiguous characters are excluded from the character set and the output.
+
+        Generates many strings with numbers, lowercase and uppercase letters and ambiguous characters excluded.
+        Asserts that none of the ambiguous characters appear and that an empty character set is rejected.
+        """
+        generator = prettyrandom.PrettyRandom(use_numbers=True, use_lowercase=True, use_uppercase=True, exclude_ambiguous=True)
+        self.assertTrue(set(generator.character_set).isdisjoint("0O1lI5S8B"))
+        for _ in range(500):
+            self.assertTrue(set(generator(4, 16)).isdisjoint("0O1lI5S8B"))
+        self.assertRaises(ValueError, prettyrandom.PrettyRandom, characters="0O1", exclude_ambiguous=True)
+        self.assertRaises(ValueError, prettyrandom.PrettyRandom, characters="0O1A", exclude_ambiguous=True)
+
+
+    def test_errors(self) -> None:
+        """
+        Test case to ensure that failures raise typed errors that remain ValueError and RuntimeError subclasses.
+
+        Constructs generators with misspelled, invalid and empty configurations, including rules, homoglyphs and
+        the Bloom filter, registers misbehaving rules, and generates impossible strings.
+        Asserts the specific error classes as well as their built-in base classes.
+        """
+        with self.assertRaises(prettyrandom.ConfigurationError):
+            prettyrandom.PrettyRandom(use_number=True)
+        with self.assertRaises(ValueError):
+            prettyrandom.PrettyRandom(swap_probability=2)
+        with self.assertRaises(prettyrandom.EmptyCharacterSetError):
+            prettyrandom.PrettyRandom(use_numbers=False, use_uppercase=False)
+        with self.assertRaises(prettyrandom.InvalidLengthError):
+            self.prettyrandom_generator(4, 2)
+        with self.assertRaises(prettyrandom.GenerationError):
+            prettyrandom.PrettyRandom(characters="A", min_transitions=1)(2, 2)
+        for options in [{'rule_selection': 'nope'}, {'rule_selection': {'repeat': -1}}, {'rules': []}, {'rules': ['nope']},
+                        {'characters': "A\u0410", 'reject_homoglyphs': True}, {'bloom_expected': 0}, {'bloom_expected': 10, 'bloom_false_positive_rate': 1}]:
+            with self.subTest(options=options):
+                self.assertRaises(prettyrandom.ConfigurationError, prettyrandom.PrettyRandom, **options)
+        broken = prettyrandom.PrettyRandom(rule_selection='repeat')
+        broken.register_rule('repeat', lambda rng, char1, char2, blocksize: char1, overwrite=True)
+        self.assertRaises(prettyrandom.ConfigurationError, broken, 4, 8)
+        self.assertRaises(prettyrandom.ConfigurationError, broken.register_rule, 'repeat', lambda rng, char1, char2, blocksize: char1)
+        self.assertTrue(issubclass(prettyrandom.GenerationError, RuntimeError))
+        self.assertTrue(issubclass(prettyrandom.InvalidLengthError, prettyrandom.PrettyRandomError))
+
+
+    def test_seed_reproducibility(self) -> None:
+        """
+        Test case to ensure that a seed yields identical output across separate runs.
+
+        Generates strings with the same seed in this process and in subprocesses with different hash seeds.
+        Asserts that all runs produce exactly the same strings.
+        """
+        script: str = (
+            "import prettyrandom\n"
+            "generator = prettyrandom.PrettyRandom(seed=42, use_lowercase=True, use_symbols=True)\n"
+            "print([generator(blocksize, 20) for blocksize in range(1, 9)])\n"
+        )
+        generator = prettyrandom.PrettyRandom(seed=42, use_lowercase=True, use_symbols=True)
+        expected: str = str([generator(blocksize, 20) for blocksize in range(1, 9)])
+        for hash_seed in ["0", "1", "random"]:
+            with self.subTest(hash_seed=hash_seed):
+                output = subprocess.run([sys.executable, "-c", script], capture_output=True, text=True, check=True,
+                                        env={**os.environ, 'PYTHONHASHSEED': hash_seed})
+                self.assertEqual(output.stdout.strip(), expected)
+
+
+    def test_use_rules(self) -> None:
+        """
+        Test case to ensure that the eligible rules can be restricted and reordered, including custom rules.
+
+        Restricts a generator to two built-in rules, then to a custom mirror rule and a built-in rule.
+        Asserts the eligible rule names and their order, that blocks follow them, that left out rules can be made eligible again,
+        and that unknown rules are rejected.
+        """
+        generator = prettyrandom.PrettyRandom(rules=['pairs', 'repeat'])
+        self.assertEqual(generator.rule_names(), ['pairs', 'repeat'])
+        for block in generator(4, 40).split(" "):
+            self.assertTrue(block[0] == block[1] and block[2] == block[3])
+
+        mirror: prettyrandom.Rule = lambda rng, char1, char2, blocksize: (char1 + char2 * (blocksize - 2) + char1)[:blocksize]
+        generator.register_rule("mirror", mirror)
+        generator.use_rules("mirror", "repeat")
+        self.assertEqual(generator.rule_names(), ['mirror', 'repeat'])
+        for block in generator(4, 40).split(" "):
+            self.assertEqual(block, block[::-1])
+
+        # Left out rules stay registered and can be made eligible again
+        generator.use_rules("pairs", "mirror")
+        self.assertEqual(generator.rule_names(), ['pairs', 'mirror'])
+        self.assertIn('zerofill', generator.rules)
+
+        self.assertRaises(ValueError, prettyrandom.PrettyRandom, rules=['unknown'])
+        self.assertRaises(ValueError, prettyrandom.PrettyRandom, rules=[])
+
+
+    def test_charset(self) -> None:
+        """
+        Test case to ensure that named and non-ASCII alphabets are used without corrupting characters.
+
+        Generates strings from the hex, base32 and Crockford alphabets, and from Greek and Cyrillic alphabets with every rule.
+        Asserts that only characters of the alphabet appear, that lengths count characters, and that unknown names are rejected.
+        """
+        for name, alphabet in [('hex', "0123456789ABCDEF"), ('base32', "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567"), ('crockford', "0123456789ABCDEFGHJKMNPQRSTVWXYZ")]:
+            with self.subTest(charset=name):
+                generator = prettyrandom.PrettyRandom(charset=name)
+                self.assertEqual(generator.character_set, sorted(alphabet))
+                self.assertTrue(set(generator(4, 40).replace(" ", "")) <= set(alphabet))
+
+        for alphabet in ["αβγδεζηθικλμξπρστυφχψω", "бвгджзклмнптфцчшщ"]:
+            generator = prettyrandom.PrettyRandom(characters=alphabet)
+            for rule in generator.rule_names():
+                with self.subTest(alphabet=alphabet, rule=rule):
+                    generator.set_rule_selection(rule)
+                    output: str = generator(5, 23)
+                    self.assertEqual([len(block) for block in output.split(" ")], [5, 5, 5, 5, 3])
+                    self.assertTrue(set(output.replace(" ", "")) <= set(alphabet))
+        self.assertRaises(ValueError, prettyrandom.PrettyRandom, charset="base58")
+        self.assertRaises(ValueError, prettyrandom.PrettyRandom, charset="hex", characters="ABC")
+
+
+    def test_exclude(self) -> None:
+        """
+        Test case to ensure that excluded characters are removed from any character set.
+
+        Excludes characters from the default and a custom character set, also combined with ambiguous characters.
+        Asserts the remaining character sets and that fewer than two remaining characters are rejected.
+        """
+        generator = prettyrandom.PrettyRandom(exclude="XYZ")
+        self.assertEqual(len(generator.character_set), 33)
+        for _ in range(200):
+            self.assertTrue(set(generator(4, 16)).isdisjoint("XYZ"))
+        self.assertEqual(prettyrandom.PrettyRandom(charset='hex', exclude="F", exclude_ambiguous=True).character_set, list("234679ACDE"))
+        self.assertRaises(ValueError, prettyrandom.PrettyRandom, characters="ABC", exclude="AB")
+
+
+    def test_template(self) -> None:
+        """
+        Test case to ensure that strings generated from a template follow its shape.
+
+        Generates strings from masks mixing any, digit and letter placeholders with literal characters.
+        Asserts that every position matches its placeholder, also with a Markov sample of letters,
+        and that masks without placeholders or characters are rejected.
+        """
+        for mask, pattern in [("XXXX-XXXX-####", r"^[0-9A-Z]{4}-[0-9A-Z]{4}-[0-9]{4}$"), ("AA#.A#X", r"^[A-Z]{2}[0-9]\.[A-Z][0-9][0-9A-Z]$"), ("#", r"^[0-9]$")]:
+            for _ in range(100):
+                with self.subTest(mask=mask):
+                    self.assertRegex(self.prettyrandom_generator.generate_from_template(mask), pattern)
+        self.assertRaises(ValueError, self.prettyrandom_generator.generate_from_template, "----")
+        self.assertRaises(ValueError, prettyrandom.PrettyRandom(use_uppercase=False).generate_from_template, "AA-##")
+
+        markov = prettyrandom.PrettyRandom(markov_sample="HELLO WORLD HELLO THERE 2024")
+        for _ in range(200):
+            self.assertRegex(markov.generate_from_template("####-AAAA-XX"), r"^[0-9]{4}-[A-Z]{4}-[0-9A-Z]{2}$")
+
+
+    def test_generate_batch(self) -> None:
+        """
+        Test case to ensure that batches are unique and skip codes that already exist.
+
+        Generates a batch while an external store reports existing codes, and requests impossible batch sizes.
+        Asserts uniqueness, that existing codes are skipped, and that exhausted alphabets raise errors.
+        """
+        issued: set[str] = set(self.prettyrandom_generator.generate_n(500, 2, 4))
+        batch: List[str] = self.prettyrandom_generator.generate_batch(2, 4, 2000, exists=lambda code: code in issued)
+        self.assertEqual(len(set(batch)), 2000)
+        self.assertTrue(issued.isdisjoint(batch))
+
+        small = prettyrandom.PrettyRandom(characters="AB")
+        self.assertRaises(prettyrandom.GenerationError, small.generate_batch, 2, 2, 5)
+        self.assertEqual(len(small.generate_batch(1, 1, 2)), 2)
+        with self.assertRaises(RuntimeError):
+            small.generate_batch(1, 1, 2, exists=lambda code: code == "A")
+
+
+    def test_entropy(self) -> None:
+        """
+        Test case to ensure that the entropy estimate reflects the pattern rules and that a minimum is enforced.
+
+        Estimates the entropy for various blocksizes, rule selections and check characters.
+        Asserts that patterns reduce the entropy below uniform characters and that low-entropy parameters are refused.
+        """
+        uniform: float = 16 * math.log2(36)
+        self.assertAlmostEqual(prettyrandom.PrettyRandom(rule_selection='repeat').entropy(1, 16), uniform)
+        self.assertLess(self.prettyrandom_generator.entropy(1, 16), uniform)
+        self.assertLess(self.prettyrandom_generator.entropy(4, 16), uniform)
+        self.assertLess(self.prettyrandom_generator.entropy(8, 16), self.prettyrandom_generator.entropy(4, 16))
+        self.assertAlmostEqual(prettyrandom.PrettyRandom(rule_selection='repeat').entropy(4, 16), 4 * math.log2(36))
+        self.assertAlmostEqual(prettyrandom.PrettyRandom(rule_selection='base64ish').entropy(4, 16), uniform)
+        self.assertLess(prettyrandom.PrettyRandom(checksum=True).entropy(4, 16), self.prettyrandom_generator.entropy(4, 16))
+
+        generator = prettyrandom.PrettyRandom(min_entropy=40)
+        self.assertEqual(len(generator(4, 16).replace(" ", "")), 16)
+        self.assertRaises(ValueError, generator, 4, 8)
+        self.assertRaises(ValueError, generator, 16, 16)
+
+
+    def test_checksum_algorithms(self) -> None:
+        """
+        Test case to ensure that both checksum algorithms produce self-verifying codes.
+
+        Generates codes with Luhn mod N and ISO 7064 MOD 37-2 check characters and introduces typos.
+        Asserts that codes validate, that single substitutions and adjacent swaps are detected, and that unsuitable sets are rejected.
+        """
+        self.assertEqual(prettyrandom.PrettyRandom.iso7064_pure_check_character("G123498654321", list("0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ")), "H")
+        for algorithm in ['luhn', 'iso7064']:
+            generator = prettyrandom.PrettyRandom(checksum=algorithm)
+            for _ in range(200):
+                with self.subTest(algorithm=algorithm):
+                    code: str = generator(4, 12)
+                    chars: str = code.replace(" ", "")
+                    self.assertTrue(generator.validate(code))
+                    position: int = generator.rng.randrange(len(chars))
+                    typo: str = "A" if chars[position] != "A" else "B"
+                    self.assertFalse(generator.validate(chars[:position] + typo + chars[position + 1:]))
+                    if algorithm == 'iso7064' and chars[4] != chars[5]:
+                        self.assertFalse(generator.validate(chars[:4] + chars[5] + chars[4] + chars[6:]))
+        self.assertRaises(ValueError, prettyrandom.PrettyRandom, checksum='verhoeff')
+        self.assertRaises(ValueError, prettyrandom.PrettyRandom, checksum='iso7064', charset='crockford')
+
+
+    def test_grouping(self) -> None:
+        """
+        Test case to ensure that the visual grouping is independent of the rule blocksize.
+
+        Generates strings with dash separators and groups of three characters from blocks of four.
+        Asserts the group sizes, that the characters are unchanged, and that invalid groupings are rejected.
+        """
+        generator = prettyrandom.PrettyRandom(separator="-", grouping=3, rule_selection='pairs')
+        for length in range(4, 30):
+            with self.subTest(length=length):
+                output: str = generator(4, length)
+                groups: List[str] = output.split("-")
+                self.assertTrue(all(len(group) == 3 for group in groups[:-1]))
+                chars: str = output.replace("-", "")
+                self.assertEqual(len(chars), length)
+                self.assertTrue(all(chars[i] == chars[i + 1] for i in range(0, length // 4 * 4, 2)))
+        self.assertNotIn(" ", prettyrandom.PrettyRandom(separator="", grouping=2)(4, 16))
+        self.assertRaises(ValueError, prettyrandom.PrettyRandom, grouping=0)
+
+
+    def test_detailed(self) -> None:
+        """
+        Test case to ensure that detailed results describe every block and serialize to JSON.
+
+        Generates detailed results with a single rule and a remainder block, and with a checksum.
+        Asserts the rule names, characters, offsets and texts of the blocks, the recipe, and the JSON round trip.
+        """
+        generator = prettyrandom.PrettyRandom(rule_selection='repeat', swap_probability=0)
+        result = generator.generate_detailed(4, 14)
+        self.assertEqual([block.offset for block in result.blocks], [0, 4, 8, 12])
+        self.assertEqual([block.rule for block in result.blocks], ['repeat', 'repeat', 'repeat', 'alternate'])
+        self.assertEqual(" ".join(block.text for block in result.blocks), result.output)
+        for block in result.blocks[:3]:
+            self.assertEqual(block.text, block.chars[0] * 4)
+        self.assertEqual(generator.generate_from_recipe(result.recipe), result.output)
+        self.assertEqual(json.loads(json.dumps(result.to_dict()))['blocks'][0]['rule'], 'repeat')
+        self.assertIsNone(generator.trace)
+
+        checked = prettyrandom.PrettyRandom(checksum=True).generate_detailed(4, 8)
+        self.assertEqual(checked.blocks[-1].text, checked.output.split(" ")[-1])
+
+
+    def test_cli(self) -> None:
+        """
+        Test case to ensure that the command line interface generates strings and reports errors.
+
+        Runs the command line interface with various flags, writing to stdout and to a file, and with invalid arguments.
+        Asserts the output, reproducibility with a seed, the exit codes, and that errors go to stderr.
+        """
+        stdout, stderr = io.StringIO(), io.StringIO()
+        args: List[str] = ["--length", "12", "--blocksize", "4", "--count", "5", "--charset", "hex", "--no-ambiguous", "--separator", "-", "--seed", "42"]
+        with contextlib.redirect_stdout(stdout):
+            self.assertEqual(cli.main(args), 0)
+        lines: List[str] = stdout.getvalue().splitlines()
+        self.assertEqual(len(lines), 5)
+        for line in lines: self.assertRegex(line, r"^[2-9A-F]{4}-[2-9A-F]{4}-[2-9A-F]{4}$")
+        self.assertFalse(set("".join(lines)) & set("058B"))
+
+        with tempfile.TemporaryDirectory() as directory:
+            path: str = os.path.join(directory, "codes.txt")
+            self.assertEqual(cli.main(args + ["--out", path]), 0)
+            with open(path, encoding="utf-8") as file:
+                self.assertEqual(file.read().splitlines(), lines)
+
+        with contextlib.redirect_stderr(stderr):
+            self.assertEqual(cli.main(["--length", "2", "--blocksize", "4"]), 1)
+            self.assertEqual(cli.main(["--out", os.path.join("missing", "directory", "codes.txt")]), 1)
+            for invalid in [["--charset", "base58"], ["--blocksize", "0"], ["--length", "0"], ["--length", "-4"], ["--count", "-1"]]:
+                with self.assertRaises(SystemExit) as context:
+                    cli.main(invalid)
+                self.assertEqual(context.exception.code, 2)
+        self.assertIn("prettyrandom: error:", stderr.getvalue())
+
+
+    def test_reader(self) -> None:
+        """
+        Test case to ensure that streamed output is formatted like generated strings.
+
+        Reads chunks of various sizes from an endless stream and writes a long string block by block.
+        Asserts the chunk sizes, the block structure of the stream, and the number of written characters.
+        """
+        stream = self.prettyrandom_generator.reader(4)
+        data: str = "".join(stream.read(size) for size in [1, 0, 7, 100, 3, 5000])
+        self.assertEqual(len(data), 5111)
+        self.assertTrue(all(len(block) == 4 for block in data.split(" ")[:-1]))
+        self.assertRaises(ValueError, stream.read)
+
+        buffer = io.StringIO()
+        written: int = self.prettyrandom_generator.generate_to(buffer, 4, 100003)
+        self.assertEqual(written, len(buffer.getvalue()))
+        blocks: List[str] = buffer.getvalue().split(" ")
+        self.assertEqual([len(block) for block in blocks], [4] * 25000 + [3])
+        self.assertRaises(ValueError, self.prettyrandom_generator.generate_to, buffer, 4, 3)
+
+
+    def test_threads(self) -> None:
+        """
+        Test case to ensure that a generator can be shared between threads.
+
+        Generates strings from many threads sharing one generator, while one thread generates within a seeded context.
+        Reads the configuration from other threads while contexts are active and alongside generation with alternating scripts and fixtures.
+        Asserts valid output, that the seeded context is not disturbed by other threads, that composite rules of clones use their own random source,
+        and that contexts only affect the thread that entered them.
+        """
+        generator = prettyrandom.PrettyRandom()
+        generator.register_composite("fancy", {'alternate': 1, 'pairs': 1})
+        with generator.seeded(7):
+            expected: List[str] = [generator(4, 16) for _ in range(200)]
+
+        def seeded_batch() -> List[str]:
+            with generator.seeded(7):
+                return [generator(4, 16) for _ in range(200)]
+
+        with ThreadPoolExecutor(max_workers=8) as executor:
+            batches = [executor.submit(lambda: [generator(4, 16) for _ in range(200)]) for _ in range(7)]
+            seeded = executor.submit(seeded_batch)
+            for batch in batches:
+                self.assertTrue(all(len(code.replace(" ", "")) == 16 for code in batch.result()))
+            self.assertEqual(seeded.result(), expected)
+
+        first, second = generator.clone(seed=1), generator.clone(seed=1)
+        first.use_rules("fancy")
+        second.use_rules("fancy")
+        self.assertEqual([first(4, 16) for _ in range(50)], [second(4, 16) for _ in range(50)])
+        self.assertIsNot(first.lock, generator.lock)
+
+        # Contexts entered by one thread are invisible to the others
+        entered, left = threading.Event(), threading.Event()
+        shared: random.Random = generator.rng
+
+        def hold_contexts() -> None:
+            with generator.seeded(1), generator.restricted(["A", "B"]):
+                entered.set()
+                left.wait()
+
+        holder = threading.Thread(target=hold_contexts)
+        holder.start()
+        entered.wait()
+        try:
+            self.assertIs(generator.rng, shared)
+            self.assertEqual(len(generator.character_set), 36)
+            self.assertFalse(generator.replaying)
+            self.assertEqual(generator.to_int("Z"), 35)
+        finally:
+            left.set()
+            holder.join()
+
+        scripts = prettyrandom.PrettyRandom(alternating_scripts=("ABCD", "αβγδ"))
+        code: str = "Aαβγδ"
+        value: int = scripts.to_int(code)
+        near: str = generator(4, 16)
+        fixtures: List[str] = generator.generate_fixtures(1, 20, 4, 16)
+        with ThreadPoolExecutor(max_workers=4) as executor:
+            generating = executor.submit(lambda: [scripts(4, 16) for _ in range(200)] + generator.generate_fixtures(1, 20, 4, 16))
+            self.assertTrue(all(scripts.to_int(code) == value for _ in range(2000)))
+            self.assertTrue(all(generator.levenshtein(generator.generate_near(near, 1).replace(" ", ""), near.replace(" ", "")) == 1 for _ in range(200)))
+            self.assertEqual(generating.result()[200:], fixtures)
+
+
+    def test_rule_weights(self) -> None:
+        """
+        Test case to ensure that rule weights are validated when the generator is constructed.
+
+        Constructs generators with weights that are zero, negative, infinite, not a number, of the wrong type or empty.
+        Asserts that all of them are rejected, and that rare rules still occur with a small positive weight.
+        """
+        for weights in [{'alternate': 0}, {'alternate': 5, 'outlier': 0}, {'alternate': -1}, {'alternate': math.inf},
+                        {'alternate': math.nan}, {'alternate': "1"}, {'alternate': True}, {}, {'unknown': 1}]:
+            with self.subTest(weights=weights):
+                self.assertRaises(ValueError, prettyrandom.PrettyRandom, rule_selection=weights)
+
+        generator = prettyrandom.PrettyRandom(rule_selection={'alternate': 45, 'pairs': 45, 'outlier': 10})
+        chosen: List = [generator.random_rule() for _ in range(10000)]
+        self.assertAlmostEqual(chosen.count(generator.outlier) / 10000, 0.1, delta=0.02)
+
+
+    def test_syllable(self) -> None:
+        """
+        Test case to ensure that the syllable rule builds pronounceable blocks and falls back without letters.
+
+        Generates codes with the pronounceable preset, also in lowercase, and applies the rule to a numbers-only set.
+        Asserts that consonants and vowels alternate, and that the rule falls back to the alternate pattern.
+        """
+        for generator in [prettyrandom.PrettyRandom.pronounceable(), prettyrandom.PrettyRandom.pronounceable(use_uppercase=False, use_lowercase=True)]:
+            for _ in range(100):
+                code: str = generator(4, 16)
+                for block in code.split(" "):
+                    self.assertEqual([char.lower() in "aeiou" for char in block], [False, True, False, True])
+        self.assertEqual(prettyrandom.PrettyRandom.pronounceable(exclude="AEIO").syllable("B", "U", 4)[1::2], "UU")
+
+        numbers = prettyrandom.PrettyRandom(use_uppercase=False)
+        self.assertEqual(numbers.syllable("1", "2", 5), "12121")
+
+
+    def test_profiles(self) -> None:
+        """
+        Test case to ensure that profiles generate their code formats and that options override them.
+
+        Generates codes from every profile, with overrides, and through the command line interface.
+        Asserts the formats, that license keys pass the license key verifier, and that unknown profiles are rejected.
+        """
+        formats: Dict[str, str] = {
+            'license_key': r"^[0-9A-Z]{5}(-[0-9A-Z]{5}){5}$",
+            'gift_card': r"^[0-9]{4}( [0-9]{4}){3}$",
+            'pin': r"^[0-9]{4}$",
+            'hex_token': r"^[0-9A-F]{8}( [0-9A-F]{8}){3}$"
+        }
+        self.assertEqual(set(formats), set(prettyrandom.PROFILES))
+        for name, pattern in formats.items():
+            generator = prettyrandom.PrettyRandom.from_profile(name)
+            for _ in range(50): self.assertRegex(generator(), pattern)
+        license_keys = prettyrandom.PrettyRandom.from_profile('license_key')
+        for _ in range(50):
+            key: str = license_keys()
+            self.assertTrue(prettyrandom.PrettyRandom.verify_license_key(key))
+            self.assertTrue(license_keys.verify_checksum_group(key))
+        self.assertTrue(prettyrandom.PrettyRandom.verify_license_key(prettyrandom.PrettyRandom.from_profile('license_key', seed=7)()))
+        self.assertRaises(ValueError, prettyrandom.PrettyRandom, checksum_group=True, separator="")
+        self.assertRaises(prettyrandom.ConfigurationError, prettyrandom.PrettyRandom.from_profile, 'license_key', checksum='iso7064')
+        self.assertRaises(prettyrandom.ConfigurationError, prettyrandom.PrettyRandom.from_profile, 'license_key', error_correction=1)
+        for options in [{'checksum': 'luhn'}, {'modulus_checksum': 97}]:
+            with self.subTest(options=options):
+                checked = prettyrandom.PrettyRandom.from_profile('license_key', **options)
+                verify = checked.validate if 'checksum' in options else checked.verify_modulus
+                for _ in range(50):
+                    key = checked()
+                    self.assertTrue(verify(key) and checked.verify_checksum_group(key))
+
+        generator = prettyrandom.PrettyRandom.from_profile('gift_card', length=8, separator="-", secure=True)
+        self.assertRegex(generator(), r"^[0-9]{4}-[0-9]{4}$")
+        self.assertRegex(generator(2, 4), r"^[0-9]{2}-[0-9]{2}$")
+        self.assertRaises(prettyrandom.ConfigurationError, prettyrandom.PrettyRandom.from_profile, 'coupon')
+        self.assertRaises(prettyrandom.ConfigurationError, prettyrandom.PrettyRandom, length=0)
+        self.assertRaises(prettyrandom.InvalidLengthError, self.prettyrandom_generator)
+
+        stdout = io.StringIO()
+        with contextlib.redirect_stdout(stdout):
+            self.assertEqual(cli.main(["--profile", "license_key", "--count", "3"]), 0)
+            self.assertEqual(cli.main(["--profile", "pin", "--length", "6", "--blocksize", "6"]), 0)
+        lines: List[str] = stdout.getvalue().splitlines()
+        for line in lines[:3]: self.assertRegex(line, formats['license_key'])
+        self.assertRegex(lines[3], r"^[0-9]{6}$")